@@ -0,0 +1,110 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+
+    "frpcx/internal/config"
+    "frpcx/internal/service"
+)
+
+const usage = `用法:
+  frpcx                                 启动图形界面
+  frpcx run [--profiles a,b]            无界面运行（用于系统服务）
+  frpcx service install [选项]          安装 systemd 服务
+  frpcx service uninstall [选项]        卸载 systemd 服务
+  frpcx service status [选项]           查看 systemd 服务状态
+
+service 选项:
+  --system          安装为系统服务（默认用户服务）
+  --name NAME       服务名（默认 frpcx）
+  --profiles a,b    使用的配置，按顺序切换
+  --run-as USER     系统服务的运行用户（默认当前用户）
+`
+
+func runCLI(cfg *config.AppConfig, args []string) int {
+    switch args[0] {
+    case "run":
+        fs := flag.NewFlagSet("run", flag.ContinueOnError)
+        profiles := fs.String("profiles", "", "")
+        if err := fs.Parse(args[1:]); err != nil {
+            fmt.Fprint(os.Stderr, usage)
+            return 2
+        }
+        if err := service.Run(cfg, splitList(*profiles)); err != nil {
+            log.Error("运行失败", "err", err)
+            return 1
+        }
+        return 0
+    case "service":
+        return runServiceCLI(args[1:])
+    case "help", "-h", "--help":
+        fmt.Print(usage)
+        return 0
+    }
+    fmt.Fprint(os.Stderr, usage)
+    return 2
+}
+
+func runServiceCLI(args []string) int {
+    if len(args) == 0 {
+        fmt.Fprint(os.Stderr, usage)
+        return 2
+    }
+    fs := flag.NewFlagSet("service", flag.ContinueOnError)
+    system := fs.Bool("system", false, "")
+    name := fs.String("name", "frpcx", "")
+    profiles := fs.String("profiles", "", "")
+    runAs := fs.String("run-as", "", "")
+    if err := fs.Parse(args[1:]); err != nil {
+        fmt.Fprint(os.Stderr, usage)
+        return 2
+    }
+    opts := service.Options{
+        Name:     *name,
+        Scope:    service.ScopeUser,
+        Profiles: splitList(*profiles),
+        RunAs:    *runAs,
+    }
+    if *system {
+        opts.Scope = service.ScopeSystem
+    }
+
+    var err error
+    switch args[0] {
+    case "install":
+        err = service.Install(opts)
+        if err == nil {
+            fmt.Printf("服务 %s 已安装并启动\n", opts.Name)
+        }
+    case "uninstall":
+        err = service.Uninstall(opts)
+        if err == nil {
+            fmt.Printf("服务 %s 已卸载\n", opts.Name)
+        }
+    case "status":
+        var out string
+        out, err = service.Status(opts)
+        fmt.Print(out)
+    default:
+        fmt.Fprint(os.Stderr, usage)
+        return 2
+    }
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+    return 0
+}
+
+func splitList(s string) []string {
+    var out []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            out = append(out, item)
+        }
+    }
+    return out
+}
@@ -0,0 +1,80 @@
+package autostart
+
+import (
+    "os"
+    "path/filepath"
+
+    "frpcx/internal/logs"
+)
+
+var log = logs.For("autostart")
+
+const (
+    appID   = "suidaohe"
+    appName = "穿透助手"
+)
+
+func Enable() error {
+    exe, err := executable()
+    if err != nil {
+        return err
+    }
+    if err := enable(exe); err != nil {
+        log.Error("开启开机自启失败", "err", err)
+        return err
+    }
+    log.Info("已开启开机自启", "exe", exe)
+    return nil
+}
+
+func Disable() error {
+    if err := disable(); err != nil {
+        log.Error("关闭开机自启失败", "err", err)
+        return err
+    }
+    log.Info("已关闭开机自启")
+    return nil
+}
+
+func Enabled() bool {
+    return enabled()
+}
+
+func Set(on bool) error {
+    if on == Enabled() {
+        return nil
+    }
+    if on {
+        return Enable()
+    }
+    return Disable()
+}
+
+func executable() (string, error) {
+    exe, err := os.Executable()
+    if err != nil {
+        return "", err
+    }
+    if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+        exe = resolved
+    }
+    return exe, nil
+}
+
+func writeFile(path string, data []byte) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return err
+    }
+    tmp := path + ".tmp"
+    if err := os.WriteFile(tmp, data, 0o644); err != nil {
+        return err
+    }
+    return os.Rename(tmp, path)
+}
+
+func removeFile(path string) error {
+    if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+        return err
+    }
+    return nil
+}
@@ -0,0 +1,60 @@
+//go:build darwin
+
+package autostart
+
+import (
+    "encoding/xml"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+func Supported() bool { return true }
+
+func plistPath() (string, error) {
+    home, err := os.UserHomeDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(home, "Library", "LaunchAgents", appID+".autostart.plist"), nil
+}
+
+func enable(exe string) error {
+    path, err := plistPath()
+    if err != nil {
+        return err
+    }
+    var b strings.Builder
+    b.WriteString(xml.Header)
+    b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+    b.WriteString("<plist version=\"1.0\">\n<dict>\n")
+    b.WriteString("  <key>Label</key>\n  <string>" + escape(appID+".autostart") + "</string>\n")
+    b.WriteString("  <key>ProgramArguments</key>\n  <array>\n    <string>" + escape(exe) + "</string>\n  </array>\n")
+    b.WriteString("  <key>RunAtLoad</key>\n  <true/>\n")
+    b.WriteString("  <key>ProcessType</key>\n  <string>Interactive</string>\n")
+    b.WriteString("</dict>\n</plist>\n")
+    return writeFile(path, []byte(b.String()))
+}
+
+func disable() error {
+    path, err := plistPath()
+    if err != nil {
+        return err
+    }
+    return removeFile(path)
+}
+
+func enabled() bool {
+    path, err := plistPath()
+    if err != nil {
+        return false
+    }
+    _, err = os.Stat(path)
+    return err == nil
+}
+
+func escape(s string) string {
+    var b strings.Builder
+    _ = xml.EscapeText(&b, []byte(s))
+    return b.String()
+}
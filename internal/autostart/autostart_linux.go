@@ -0,0 +1,69 @@
+//go:build linux
+
+package autostart
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+func Supported() bool { return true }
+
+func entryPath() (string, error) {
+    dir := os.Getenv("XDG_CONFIG_HOME")
+    if dir == "" {
+        home, err := os.UserHomeDir()
+        if err != nil {
+            return "", err
+        }
+        dir = filepath.Join(home, ".config")
+    }
+    return filepath.Join(dir, "autostart", appID+".desktop"), nil
+}
+
+func enable(exe string) error {
+    path, err := entryPath()
+    if err != nil {
+        return err
+    }
+    var b strings.Builder
+    b.WriteString("[Desktop Entry]\n")
+    b.WriteString("Type=Application\n")
+    b.WriteString("Name=" + appName + "\n")
+    b.WriteString("Exec=" + quoteExec(exe) + "\n")
+    b.WriteString("Terminal=false\n")
+    b.WriteString("X-GNOME-Autostart-enabled=true\n")
+    return writeFile(path, []byte(b.String()))
+}
+
+func disable() error {
+    path, err := entryPath()
+    if err != nil {
+        return err
+    }
+    return removeFile(path)
+}
+
+func enabled() bool {
+    path, err := entryPath()
+    if err != nil {
+        return false
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return false
+    }
+    for _, line := range strings.Split(string(data), "\n") {
+        line = strings.TrimSpace(line)
+        if line == "Hidden=true" || line == "X-GNOME-Autostart-enabled=false" {
+            return false
+        }
+    }
+    return true
+}
+
+func quoteExec(s string) string {
+    r := strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", `$`, `\\$`, `%`, `%%`)
+    return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !windows
+
+package autostart
+
+import "errors"
+
+var errUnsupported = errors.New("当前系统不支持开机自启")
+
+func Supported() bool { return false }
+
+func enable(string) error { return errUnsupported }
+
+func disable() error { return nil }
+
+func enabled() bool { return false }
@@ -0,0 +1,42 @@
+//go:build windows
+
+package autostart
+
+import (
+    "fmt"
+    "os/exec"
+    "strings"
+    "syscall"
+)
+
+const runKey = `HKCU\Software\Microsoft\Windows\CurrentVersion\Run`
+
+func Supported() bool { return true }
+
+func enable(exe string) error {
+    return reg("add", runKey, "/v", appID, "/t", "REG_SZ", "/d", `"`+exe+`"`, "/f")
+}
+
+func disable() error {
+    if !enabled() {
+        return nil
+    }
+    return reg("delete", runKey, "/v", appID, "/f")
+}
+
+func enabled() bool {
+    return reg("query", runKey, "/v", appID) == nil
+}
+
+func reg(args ...string) error {
+    cmd := exec.Command("reg", args...)
+    cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+    out, err := cmd.CombinedOutput()
+    if err != nil {
+        if msg := strings.TrimSpace(string(out)); msg != "" {
+            return fmt.Errorf("%s: %w", msg, err)
+        }
+        return err
+    }
+    return nil
+}
@@ -7,11 +7,65 @@ import (
 )
 
 type AppConfig struct {
-    Version        int          `json:"version"`
-    AutoSwitch     bool         `json:"auto_switch"`
-    ActiveProfile  string       `json:"active_profile"`
-    Profiles       []Profile    `json:"profiles"`
-    WebDAV         WebDAVConfig `json:"webdav"`
+    Version         int            `json:"version"`
+    AutoSwitch      bool           `json:"auto_switch"`
+    Strategy        string         `json:"strategy,omitempty"`
+    FailBack        FailBackConfig `json:"failback"`
+    Breaker         BreakerConfig  `json:"breaker"`
+    NetWatch        NetWatchConfig `json:"net_watch"`
+    ActiveProfile   string         `json:"active_profile"`
+    Profiles        []Profile      `json:"profiles"`
+    WebDAV          WebDAVConfig   `json:"webdav"`
+    Logs            LogConfig      `json:"logs"`
+    Metrics         MetricsConfig  `json:"metrics"`
+    Notify          NotifyConfig   `json:"notify"`
+    Autostart       bool           `json:"autostart"`
+    AutoStartTunnel bool           `json:"auto_start_tunnel"`
+}
+
+type NotifyConfig struct {
+    OnStart        bool `json:"on_start"`
+    OnFailure      bool `json:"on_failure"`
+    OnFailover     bool `json:"on_failover"`
+    OnDegraded     bool `json:"on_degraded"`
+    MinIntervalSec int  `json:"min_interval_sec"`
+}
+
+type FailBackConfig struct {
+    Enabled     bool `json:"enabled"`
+    IntervalSec int  `json:"interval_sec"`
+    StableSec   int  `json:"stable_sec"`
+    HoldSec     int  `json:"hold_sec"`
+}
+
+type BreakerConfig struct {
+    Threshold      int `json:"threshold"`
+    CooldownSec    int `json:"cooldown_sec"`
+    MaxCooldownSec int `json:"max_cooldown_sec"`
+}
+
+type NetWatchConfig struct {
+    Enabled bool `json:"enabled"`
+    PollSec int  `json:"poll_sec"`
+}
+
+type MetricsConfig struct {
+    Enabled     bool   `json:"enabled"`
+    Listen      string `json:"listen"`
+    IntervalSec int    `json:"interval_sec"`
+}
+
+type DashboardConfig struct {
+    URL      string `json:"url"`
+    Username string `json:"username"`
+    Password string `json:"password"`
+}
+
+type LogConfig struct {
+    Level      string `json:"level"`
+    MaxSizeMB  int    `json:"max_size_mb"`
+    MaxAgeDays int    `json:"max_age_days"`
+    MaxBackups int    `json:"max_backups"`
 }
 
 type WebDAVConfig struct {
@@ -22,20 +76,54 @@ type WebDAVConfig struct {
 }
 
 type Profile struct {
-    Name              string   `json:"name"`
-    Enabled           bool     `json:"enabled"`
-    FrpcPath          string   `json:"frpc_path"`
-    ConfigPath        string   `json:"config_path"`
-    RemoteConfigPath  string   `json:"remote_config_path"`
-    ServerAddr        string   `json:"server_addr"`
-    ServerPort        int      `json:"server_port"`
-    LocalCheckPorts   []int    `json:"local_check_ports"`
-    StartTimeoutSec   int      `json:"start_timeout_sec"`
-    HealthTimeoutSec  int      `json:"health_timeout_sec"`
-    RequireStatus     bool     `json:"require_status"`
-    StatusTimeoutSec  int      `json:"status_timeout_sec"`
-    StatusIntervalSec int      `json:"status_interval_sec"`
-    ExtraArgs         []string `json:"extra_args"`
+    Name              string            `json:"name"`
+    Enabled           bool              `json:"enabled"`
+    FrpcPath          string            `json:"frpc_path"`
+    FrpcVersion       string            `json:"frpc_version,omitempty"`
+    ConvertToIni      bool              `json:"convert_to_ini,omitempty"`
+    ConfigPath        string            `json:"config_path"`
+    RemoteConfigPath  string            `json:"remote_config_path"`
+    Template          string            `json:"template,omitempty"`
+    Vars              map[string]string `json:"vars,omitempty"`
+    ServerAddr        string            `json:"server_addr"`
+    ServerPort        int               `json:"server_port"`
+    ProxyURL          string            `json:"proxy_url,omitempty"`
+    UseSystemProxy    bool              `json:"use_system_proxy,omitempty"`
+    LocalCheckPorts   []int             `json:"local_check_ports"`
+    StartTimeoutSec   int               `json:"start_timeout_sec"`
+    HealthTimeoutSec  int               `json:"health_timeout_sec"`
+    RequireStatus     bool              `json:"require_status"`
+    StatusTimeoutSec  int               `json:"status_timeout_sec"`
+    StatusIntervalSec int               `json:"status_interval_sec"`
+    ExtraArgs         []string          `json:"extra_args"`
+    StopGraceSec      int               `json:"stop_grace_sec"`
+    Dashboard         DashboardConfig   `json:"dashboard"`
+    Hooks             []Hook            `json:"hooks"`
+    Backend           string            `json:"backend,omitempty"`
+    SSH               SSHConfig         `json:"ssh"`
+}
+
+type SSHConfig struct {
+    Host         string       `json:"host"`
+    Port         int          `json:"port"`
+    User         string       `json:"user"`
+    IdentityFile string       `json:"identity_file"`
+    Options      []string     `json:"options"`
+    Forwards     []SSHForward `json:"forwards"`
+}
+
+type SSHForward struct {
+    RemoteBind string `json:"remote_bind,omitempty"`
+    RemotePort int    `json:"remote_port"`
+    LocalHost  string `json:"local_host,omitempty"`
+    LocalPort  int    `json:"local_port"`
+}
+
+type Hook struct {
+    Event      string `json:"event"`
+    Command    string `json:"command,omitempty"`
+    URL        string `json:"url,omitempty"`
+    TimeoutSec int    `json:"timeout_sec"`
 }
 
 func DefaultConfig() *AppConfig {
@@ -45,6 +133,39 @@ func DefaultConfig() *AppConfig {
         ActiveProfile: "",
         Profiles:      []Profile{},
         WebDAV:        WebDAVConfig{},
+        Logs: LogConfig{
+            Level:      "info",
+            MaxSizeMB:  5,
+            MaxAgeDays: 7,
+            MaxBackups: 5,
+        },
+        FailBack: FailBackConfig{
+            Enabled:     false,
+            IntervalSec: 30,
+            StableSec:   120,
+            HoldSec:     300,
+        },
+        Breaker: BreakerConfig{
+            Threshold:      3,
+            CooldownSec:    30,
+            MaxCooldownSec: 600,
+        },
+        NetWatch: NetWatchConfig{
+            Enabled: true,
+            PollSec: 15,
+        },
+        Metrics: MetricsConfig{
+            Enabled:     false,
+            Listen:      "127.0.0.1:9797",
+            IntervalSec: 5,
+        },
+        Notify: NotifyConfig{
+            OnStart:        true,
+            OnFailure:      true,
+            OnFailover:     true,
+            OnDegraded:     true,
+            MinIntervalSec: 60,
+        },
     }
 }
 
@@ -72,6 +193,22 @@ func CacheDir() (string, error) {
     return filepath.Join(dir, "cache"), nil
 }
 
+func RuntimeStatePath() (string, error) {
+    dir, err := ConfigDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(dir, "run", "state.json"), nil
+}
+
+func LogDir() (string, error) {
+    dir, err := ConfigDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(dir, "logs"), nil
+}
+
 func (c *AppConfig) Clone() *AppConfig {
     b, _ := json.Marshal(c)
     var out AppConfig
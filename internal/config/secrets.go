@@ -0,0 +1,139 @@
+package config
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "sync"
+)
+
+const OIDCSecretEnv = "FRP_OIDC_CLIENT_SECRET"
+
+var secretsMu sync.Mutex
+
+var secretEnvs = []string{OIDCSecretEnv}
+
+func ScrubEnv(env []string) []string {
+    out := make([]string, 0, len(env))
+    for _, kv := range env {
+        k, _, _ := strings.Cut(kv, "=")
+        hidden := false
+        for _, s := range secretEnvs {
+            if strings.EqualFold(k, s) {
+                hidden = true
+                break
+            }
+        }
+        if !hidden {
+            out = append(out, kv)
+        }
+    }
+    return out
+}
+
+func SecretsPath() (string, error) {
+    dir, err := ConfigDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(dir, "secrets.json"), nil
+}
+
+func GetSecret(key string) (string, error) {
+    secretsMu.Lock()
+    defer secretsMu.Unlock()
+    m, err := loadSecrets()
+    if err != nil {
+        return "", err
+    }
+    return m[key], nil
+}
+
+func SetSecret(key, value string) error {
+    secretsMu.Lock()
+    defer secretsMu.Unlock()
+    m, err := loadSecrets()
+    if err != nil {
+        return err
+    }
+    if m[key] == value {
+        return nil
+    }
+    if value == "" {
+        delete(m, key)
+    } else {
+        m[key] = value
+    }
+    path, err := SecretsPath()
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return err
+    }
+    b, err := json.MarshalIndent(m, "", "  ")
+    if err != nil {
+        return err
+    }
+    tmp, err := os.CreateTemp(filepath.Dir(path), ".secrets-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+    if _, err := tmp.Write(b); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+        return err
+    }
+    if err := os.Rename(tmp.Name(), path); err != nil {
+        log.Error("保存密钥失败", "path", path, "err", err)
+        return err
+    }
+    return nil
+}
+
+func loadSecrets() (map[string]string, error) {
+    path, err := SecretsPath()
+    if err != nil {
+        return nil, err
+    }
+    m := map[string]string{}
+    if err := checkSecretsMode(path); err != nil {
+        if errors.Is(err, os.ErrNotExist) {
+            return m, nil
+        }
+        return nil, err
+    }
+    b, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(b, &m); err != nil {
+        return nil, err
+    }
+    return m, nil
+}
+
+func checkSecretsMode(path string) error {
+    st, err := os.Stat(path)
+    if err != nil {
+        return err
+    }
+    if runtime.GOOS == "windows" || st.Mode().Perm()&0o077 == 0 {
+        return nil
+    }
+    log.Warn("密钥文件权限过宽，已收紧为 0600", "path", path, "mode", st.Mode().Perm())
+    if err := os.Chmod(path, 0o600); err != nil {
+        return fmt.Errorf("密钥文件 %s 权限过宽（%v），且无法改为 0600: %w", path, st.Mode().Perm(), err)
+    }
+    return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+    "os"
+    "runtime"
+    "testing"
+)
+
+func useTempDir(t *testing.T) {
+    t.Helper()
+    dir := t.TempDir()
+    t.Setenv("XDG_CONFIG_HOME", dir)
+    t.Setenv("HOME", dir)
+    t.Setenv("AppData", dir)
+}
+
+func TestSecrets(t *testing.T) {
+    useTempDir(t)
+    if v, err := GetSecret("oidc/home"); err != nil || v != "" {
+        t.Fatalf("empty store = %q, %v", v, err)
+    }
+    if err := SetSecret("oidc/home", "s3cret"); err != nil {
+        t.Fatal(err)
+    }
+    if v, err := GetSecret("oidc/home"); err != nil || v != "s3cret" {
+        t.Fatalf("GetSecret = %q, %v", v, err)
+    }
+    if err := SetSecret("oidc/home", ""); err != nil {
+        t.Fatal(err)
+    }
+    if v, _ := GetSecret("oidc/home"); v != "" {
+        t.Fatalf("deleted secret = %q", v)
+    }
+}
+
+func TestSecretsFileMode(t *testing.T) {
+    if runtime.GOOS == "windows" {
+        t.Skip("no unix permissions")
+    }
+    useTempDir(t)
+    if err := SetSecret("oidc/home", "s3cret"); err != nil {
+        t.Fatal(err)
+    }
+    path, _ := SecretsPath()
+    st, err := os.Stat(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if st.Mode().Perm() != 0o600 {
+        t.Fatalf("mode = %v, want 0600", st.Mode().Perm())
+    }
+
+    if err := os.Chmod(path, 0o644); err != nil {
+        t.Fatal(err)
+    }
+    if v, err := GetSecret("oidc/home"); err != nil || v != "s3cret" {
+        t.Fatalf("GetSecret = %q, %v", v, err)
+    }
+    st, _ = os.Stat(path)
+    if st.Mode().Perm() != 0o600 {
+        t.Fatalf("mode after read = %v, want 0600", st.Mode().Perm())
+    }
+}
+
+func TestScrubEnv(t *testing.T) {
+    env := []string{"PATH=/bin", OIDCSecretEnv + "=x", "frp_oidc_client_secret=y", "HOME=/root"}
+    got := ScrubEnv(env)
+    if len(got) != 2 || got[0] != "PATH=/bin" || got[1] != "HOME=/root" {
+        t.Fatalf("ScrubEnv = %v", got)
+    }
+}
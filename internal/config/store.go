@@ -4,8 +4,12 @@ import (
     "encoding/json"
     "errors"
     "os"
+
+    "frpcx/internal/logs"
 )
 
+var log = logs.For("config")
+
 func Load() (*AppConfig, error) {
     path, err := ConfigPath()
     if err != nil {
@@ -14,19 +18,25 @@ func Load() (*AppConfig, error) {
     b, err := os.ReadFile(path)
     if err != nil {
         if errors.Is(err, os.ErrNotExist) {
+            log.Info("配置不存在，使用默认配置", "path", path)
             cfg := DefaultConfig()
             _ = Save(cfg)
             return cfg, nil
         }
         return nil, err
     }
-    var cfg AppConfig
+    defaults := DefaultConfig()
+    cfg := AppConfig{Notify: defaults.Notify, NetWatch: defaults.NetWatch}
     if err := json.Unmarshal(b, &cfg); err != nil {
         return nil, err
     }
     if cfg.Version == 0 {
         cfg.Version = 1
     }
+    if cfg.Metrics.Listen == "" {
+        cfg.Metrics.Listen = defaults.Metrics.Listen
+    }
+    log.Debug("配置已加载", "path", path, "profiles", len(cfg.Profiles))
     return &cfg, nil
 }
 
@@ -46,5 +56,9 @@ func Save(cfg *AppConfig) error {
     if err != nil {
         return err
     }
-    return os.WriteFile(path, b, 0o600)
+    if err := os.WriteFile(path, b, 0o600); err != nil {
+        log.Error("保存配置失败", "path", path, "err", err)
+        return err
+    }
+    return nil
 }
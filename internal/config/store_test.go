@@ -0,0 +1,59 @@
+package config
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func writeConfig(t *testing.T, body string) {
+    t.Helper()
+    path, err := ConfigPath()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestLoadKeepsDisabledSections(t *testing.T) {
+    useTempDir(t)
+    writeConfig(t, `{
+  "notify": {"on_start": false, "on_failure": false, "on_failover": false, "on_degraded": false, "min_interval_sec": 0},
+  "net_watch": {"enabled": false, "poll_sec": 0}
+}`)
+    cfg, err := Load()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if cfg.Notify != (NotifyConfig{}) {
+        t.Fatalf("notify = %+v, want all off", cfg.Notify)
+    }
+    if cfg.NetWatch != (NetWatchConfig{}) {
+        t.Fatalf("net_watch = %+v, want disabled", cfg.NetWatch)
+    }
+}
+
+func TestLoadFillsMissingSections(t *testing.T) {
+    useTempDir(t)
+    writeConfig(t, `{"notify": {"on_start": false}}`)
+    cfg, err := Load()
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := DefaultConfig()
+    want.Notify.OnStart = false
+    if cfg.Notify != want.Notify {
+        t.Fatalf("notify = %+v, want %+v", cfg.Notify, want.Notify)
+    }
+    if cfg.NetWatch != want.NetWatch {
+        t.Fatalf("net_watch = %+v, want %+v", cfg.NetWatch, want.NetWatch)
+    }
+    if cfg.Metrics.Listen != want.Metrics.Listen {
+        t.Fatalf("metrics.listen = %q", cfg.Metrics.Listen)
+    }
+}
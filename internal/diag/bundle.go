@@ -0,0 +1,221 @@
+package diag
+
+import (
+    "archive/zip"
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/url"
+    "os"
+    "runtime"
+    "strings"
+    "time"
+
+    "frpcx/internal/config"
+    "frpcx/internal/frpc"
+    "frpcx/internal/logs"
+)
+
+var log = logs.For("diag")
+
+const redacted = "******"
+
+func CreateBundle(w io.Writer, cfg *config.AppConfig, mgr *frpc.Manager) error {
+    zw := zip.NewWriter(w)
+
+    if err := writeJSON(zw, "config.json", RedactConfig(cfg)); err != nil {
+        _ = zw.Close()
+        return err
+    }
+
+    for _, p := range cfg.Profiles {
+        if p.ConfigPath == "" {
+            continue
+        }
+        b, err := os.ReadFile(p.ConfigPath)
+        if err != nil {
+            _ = writeText(zw, "profiles/"+logs.SafeName(p.Name)+".error.txt", err.Error())
+            continue
+        }
+        if err := writeText(zw, "profiles/"+logs.SafeName(p.Name)+".toml", RedactToml(string(b))); err != nil {
+            _ = zw.Close()
+            return err
+        }
+    }
+
+    if err := writeText(zw, "frpc-version.txt", frpcVersions(cfg)); err != nil {
+        _ = zw.Close()
+        return err
+    }
+    if err := writeText(zw, "system.txt", systemInfo()); err != nil {
+        _ = zw.Close()
+        return err
+    }
+
+    status := struct {
+        Current frpc.StatusSnapshot `json:"current"`
+        History []frpc.StatusRecord `json:"history"`
+    }{
+        Current: mgr.Status(),
+        History: mgr.History(),
+    }
+    status.Current.LogLines = nil
+    if err := writeJSON(zw, "status.json", status); err != nil {
+        _ = zw.Close()
+        return err
+    }
+
+    if err := logs.Shared().WriteTo(zw, "logs"); err != nil {
+        _ = zw.Close()
+        return err
+    }
+
+    if err := zw.Close(); err != nil {
+        return err
+    }
+    log.Info("已生成诊断包", "profiles", len(cfg.Profiles))
+    return nil
+}
+
+func RedactConfig(cfg *config.AppConfig) *config.AppConfig {
+    out := cfg.Clone()
+    if out.WebDAV.Password != "" {
+        out.WebDAV.Password = redacted
+    }
+    if out.WebDAV.Username != "" {
+        out.WebDAV.Username = redacted
+    }
+    for i := range out.Profiles {
+        if out.Profiles[i].Dashboard.Password != "" {
+            out.Profiles[i].Dashboard.Password = redacted
+        }
+        out.Profiles[i].ProxyURL = frpc.RedactProxyURL(out.Profiles[i].ProxyURL)
+        for j := range out.Profiles[i].Hooks {
+            h := &out.Profiles[i].Hooks[j]
+            h.URL = redactURL(h.URL)
+            h.Command = redactCommand(h.Command)
+        }
+        for k := range out.Profiles[i].Vars {
+            if isSecretKey(k) {
+                out.Profiles[i].Vars[k] = redacted
+            }
+        }
+    }
+    return out
+}
+
+func redactURL(raw string) string {
+    if raw == "" {
+        return ""
+    }
+    u, err := url.Parse(raw)
+    if err != nil || u.Host == "" {
+        return redacted
+    }
+    out := u.Scheme + "://" + u.Host
+    if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+        out += "/…"
+    }
+    return out
+}
+
+func redactCommand(cmd string) string {
+    fields := strings.Fields(cmd)
+    if len(fields) <= 1 {
+        return cmd
+    }
+    return fields[0] + " " + redacted
+}
+
+func RedactToml(content string) string {
+    var b strings.Builder
+    sc := bufio.NewScanner(strings.NewReader(content))
+    for sc.Scan() {
+        line := sc.Text()
+        if key, _, ok := strings.Cut(line, "="); ok && isSecretKey(key) {
+            line = key + "= \"" + redacted + "\""
+        }
+        b.WriteString(line)
+        b.WriteString("\n")
+    }
+    return b.String()
+}
+
+func isSecretKey(key string) bool {
+    k := strings.ToLower(strings.TrimSpace(key))
+    if strings.HasPrefix(k, "#") {
+        return false
+    }
+    for _, s := range []string{"token", "secret", "password", "passwd", "proxyurl"} {
+        if strings.Contains(k, s) {
+            return true
+        }
+    }
+    return false
+}
+
+func frpcVersions(cfg *config.AppConfig) string {
+    var b strings.Builder
+    seen := map[string]bool{}
+    for _, p := range cfg.Profiles {
+        if frpc.UsesLibrary(&p) {
+            if !seen[""] {
+                seen[""] = true
+                v, _ := frpc.ProfileVersion(&p)
+                fmt.Fprintf(&b, "进程内 frp 库: %s\n", v)
+            }
+            continue
+        }
+        path, err := frpc.ResolveProfileBinary(&p)
+        if err != nil {
+            fmt.Fprintf(&b, "%s: %v\n", p.Name, err)
+            continue
+        }
+        if seen[path] {
+            continue
+        }
+        seen[path] = true
+        v, err := frpc.BinaryVersion(path)
+        if err != nil {
+            fmt.Fprintf(&b, "%s: %v\n", path, err)
+            continue
+        }
+        fmt.Fprintf(&b, "%s: %s\n", path, v)
+    }
+    if b.Len() == 0 {
+        b.WriteString("没有配置\n")
+    }
+    return b.String()
+}
+
+func systemInfo() string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "time: %s\n", time.Now().Format(time.RFC3339))
+    fmt.Fprintf(&b, "os: %s\n", runtime.GOOS)
+    fmt.Fprintf(&b, "arch: %s\n", runtime.GOARCH)
+    fmt.Fprintf(&b, "go: %s\n", runtime.Version())
+    fmt.Fprintf(&b, "cpus: %d\n", runtime.NumCPU())
+    if b2, err := os.ReadFile("/etc/os-release"); err == nil {
+        b.WriteString("\n/etc/os-release:\n")
+        b.Write(b2)
+    }
+    return b.String()
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+    b, err := json.MarshalIndent(v, "", "  ")
+    if err != nil {
+        return err
+    }
+    return writeText(zw, name, string(b))
+}
+
+func writeText(zw *zip.Writer, name, content string) error {
+    w, err := zw.Create(name)
+    if err != nil {
+        return err
+    }
+    _, err = io.WriteString(w, content)
+    return err
+}
@@ -0,0 +1,294 @@
+package frpc
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "strings"
+    "sync"
+    "time"
+
+    "frpcx/internal/config"
+)
+
+const (
+    BackendFrpc = "frpc"
+    BackendSSH  = "ssh"
+)
+
+type Backend interface {
+    Prepare(ctx context.Context) error
+    Start(onLine func(string)) error
+    Ready(ctx context.Context) error
+    Health(ctx context.Context) error
+    Stop(grace time.Duration)
+    Done() <-chan struct{}
+    Err() error
+}
+
+type processInfo interface {
+    Pid() int
+    Binary() string
+    ConfigPath() string
+}
+
+type adoptable interface {
+    adopt(rec RunRecord)
+}
+
+func newBackend(p *config.Profile) (Backend, error) {
+    switch backendName(p) {
+    case BackendFrpc:
+        return &frpcBackend{profile: p}, nil
+    case BackendSSH:
+        return &sshBackend{profile: p}, nil
+    }
+    return nil, fmt.Errorf("未知的隧道类型: %s", p.Backend)
+}
+
+func backendName(p *config.Profile) string {
+    if p.Backend == "" {
+        return BackendFrpc
+    }
+    return p.Backend
+}
+
+type procBackend struct {
+    proc      runner
+    ready     chan struct{}
+    failed    chan error
+    readyOnce sync.Once
+}
+
+func (b *procBackend) init() {
+    b.ready = make(chan struct{})
+    b.failed = make(chan error, 1)
+}
+
+func (b *procBackend) markReady() {
+    b.readyOnce.Do(func() { close(b.ready) })
+}
+
+func (b *procBackend) markFailed(err error) {
+    select {
+    case b.failed <- err:
+    default:
+    }
+}
+
+func (b *procBackend) Ready(ctx context.Context) error {
+    select {
+    case err := <-b.failed:
+        return err
+    case <-b.ready:
+        return nil
+    case <-b.proc.Done():
+        if err := b.proc.Err(); err != nil {
+            return fmt.Errorf("进程提前退出: %w", err)
+        }
+        return errors.New("进程退出")
+    case <-ctx.Done():
+        return errors.New("启动超时")
+    }
+}
+
+func (b *procBackend) adopt(rec RunRecord) {
+    b.init()
+    b.proc = adoptProcess(rec.PID)
+    b.markReady()
+}
+
+func (b *procBackend) Pid() int {
+    if b.proc == nil {
+        return 0
+    }
+    return b.proc.Pid()
+}
+
+func (b *procBackend) Done() <-chan struct{} {
+    return b.proc.Done()
+}
+
+func (b *procBackend) Err() error {
+    return b.proc.Err()
+}
+
+func (b *procBackend) Stop(grace time.Duration) {
+    if b.proc != nil {
+        b.proc.Stop(grace)
+    }
+}
+
+type frpcBackend struct {
+    procBackend
+    profile  *config.Profile
+    cfgPath  string
+    runCfg   string
+    frpcPath string
+    env      []string
+    secret   string
+    proxyURL string
+    proxyCfg string
+    run      string
+    runErr   error
+    release  func()
+}
+
+func (b *frpcBackend) Prepare(ctx context.Context) error {
+    cfgPath, err := resolveConfigPath(b.profile)
+    if err != nil {
+        return err
+    }
+    frpcPath, err := resolveRunBinary(b.profile)
+    if err != nil {
+        return err
+    }
+    run, release, err := stageBinary(frpcPath)
+    if err != nil {
+        return err
+    }
+    prepared := false
+    defer func() {
+        if !prepared {
+            release()
+        }
+    }()
+    if info, err := LoadClientInfo(cfgPath); err == nil {
+        if err := info.Transport.Validate(); err != nil {
+            return fmt.Errorf("传输设置无效: %w", err)
+        }
+        if err := ValidateVisitors(info.Visitors); err != nil {
+            return err
+        }
+        if err := checkVisitorBinds(info.Visitors); err != nil {
+            return err
+        }
+        if err := b.checkOIDC(ctx, info); err != nil {
+            return err
+        }
+    }
+    proxyURL, proxyCfg, err := applyProxy(cfgPath, b.profile)
+    if err != nil {
+        return err
+    }
+    src := cfgPath
+    if proxyCfg != "" {
+        src = proxyCfg
+    }
+    runCfg, err := prepareConfig(frpcPath, src, b.profile)
+    if err != nil {
+        _ = os.Remove(proxyCfg)
+        return err
+    }
+    if proxyURL != "" {
+        b.env = append(b.env, "http_proxy="+proxyURL)
+    }
+    b.proxyURL = proxyURL
+    b.proxyCfg = proxyCfg
+    b.cfgPath = cfgPath
+    b.frpcPath = frpcPath
+    b.runCfg = runCfg
+    b.run = run
+    b.release = release
+    prepared = true
+    b.init()
+    return nil
+}
+
+func (b *frpcBackend) Start(onLine func(string)) error {
+    p := b.profile
+    if b.frpcPath == "" {
+        if len(p.ExtraArgs) > 0 {
+            log.Warn("进程内模式不支持额外参数，已忽略", "profile", p.Name, "args", strings.Join(p.ExtraArgs, " "))
+        }
+        proc, err := startLibrary(b.runCfg, b.secret, b.proxyURL, onLine, b.markReady, b.markFailed)
+        if err != nil {
+            return err
+        }
+        b.proc = proc
+        return nil
+    }
+
+    cmd := exec.Command(b.run, append([]string{"-c", b.runCfg}, p.ExtraArgs...)...)
+    cmd.Env = append(config.ScrubEnv(os.Environ()), b.env...)
+    proc, err := startProcess(cmd, func(line string) {
+        onLine(line)
+        if ok, err := classifyLog(line); ok {
+            b.markReady()
+        } else if err != nil {
+            b.markFailed(err)
+        }
+    })
+    if err != nil {
+        b.releaseRun()
+        return err
+    }
+    b.proc = proc
+    return nil
+}
+
+func (b *frpcBackend) Health(ctx context.Context) error {
+    if b.frpcPath == "" {
+        if lib, ok := b.proc.(interface{ CheckStatus() error }); ok {
+            return lib.CheckStatus()
+        }
+        return errors.New("frpc 未运行")
+    }
+    if b.runErr != nil {
+        return b.runErr
+    }
+    cmd := exec.CommandContext(ctx, b.run, "status", "-c", b.runCfg)
+    cmd.Env = append(config.ScrubEnv(os.Environ()), b.env...)
+    out, err := cmd.CombinedOutput()
+    if err != nil {
+        msg := strings.TrimSpace(string(out))
+        if msg == "" {
+            msg = err.Error()
+        }
+        return errors.New(msg)
+    }
+    return nil
+}
+
+func (b *frpcBackend) Stop(grace time.Duration) {
+    b.procBackend.Stop(grace)
+    b.releaseRun()
+    if b.proxyCfg != "" {
+        _ = os.Remove(b.proxyCfg)
+    }
+}
+
+func (b *frpcBackend) releaseRun() {
+    if b.release != nil {
+        b.release()
+    }
+}
+
+func (b *frpcBackend) reportsStatus() bool {
+    if b.frpcPath == "" {
+        return b.proc != nil
+    }
+    info, err := LoadClientInfo(b.runCfg)
+    return err == nil && info.WebServerPort > 0
+}
+
+func (b *frpcBackend) adopt(rec RunRecord) {
+    b.procBackend.adopt(rec)
+    b.frpcPath = rec.FrpcPath
+    b.cfgPath = rec.ConfigPath
+    b.runCfg = rec.ConfigPath
+    b.run, b.release, b.runErr = stageBinary(rec.FrpcPath)
+    if c, err := prepareConfig(rec.FrpcPath, rec.ConfigPath, b.profile); err == nil {
+        b.runCfg = c
+    }
+}
+
+func (b *frpcBackend) Binary() string {
+    return b.frpcPath
+}
+
+func (b *frpcBackend) ConfigPath() string {
+    return b.cfgPath
+}
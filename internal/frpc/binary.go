@@ -1,15 +1,48 @@
 package frpc
 
 import (
+    "bytes"
+    "context"
     "crypto/sha256"
     "fmt"
+    "io"
     "os"
+    "os/exec"
     "path/filepath"
-    "runtime"
+    "strings"
+    "sync"
+    "time"
 
     "frpcx/internal/config"
 )
 
+var (
+    embeddedOnce   sync.Once
+    embeddedDigest [sha256.Size]byte
+    embeddedOK     bool
+
+    runsMu     sync.Mutex
+    activeRuns = map[string]bool{}
+)
+
+func embeddedSum() ([sha256.Size]byte, bool) {
+    embeddedOnce.Do(func() {
+        if _, data, ok := embeddedBinary(); ok {
+            embeddedDigest = sha256.Sum256(data)
+            embeddedOK = true
+        }
+    })
+    return embeddedDigest, embeddedOK
+}
+
+func embeddedDir() (string, error) {
+    dir, err := config.CacheDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(dir, "embedded"), nil
+}
+
 func ResolveBinaryPath(userPath string) (string, error) {
     if userPath != "" {
         return userPath, nil
@@ -20,36 +53,162 @@ func ResolveBinaryPath(userPath string) (string, error) {
         return "", fmt.Errorf("当前构建未内置 frpc，请下载带内置 frpc 的发布版本")
     }
 
-    dir, err := config.CacheDir()
+    binDir, err := embeddedDir()
     if err != nil {
         return "", err
     }
-    binDir := filepath.Join(dir, "bin")
-    if err := os.MkdirAll(binDir, 0o755); err != nil {
+    if err := os.MkdirAll(binDir, 0o700); err != nil {
         return "", err
     }
+    if err := os.Chmod(binDir, 0o700); err != nil {
+        return "", err
+    }
+    removeLegacyBinary(name)
+    removeStaleRuns(binDir)
 
     dst := filepath.Join(binDir, name)
     if err := writeIfChanged(dst, data); err != nil {
         return "", err
     }
 
-    if runtime.GOOS != "windows" {
-        _ = os.Chmod(dst, 0o755)
+    return dst, nil
+}
+
+func UsesLibrary(p *config.Profile) bool {
+    return libraryAvailable && p.FrpcPath == "" && p.FrpcVersion == ""
+}
+
+func resolveRunBinary(p *config.Profile) (string, error) {
+    if UsesLibrary(p) {
+        return "", nil
+    }
+    return ResolveProfileBinary(p)
+}
+
+func stageBinary(path string) (string, func(), error) {
+    noop := func() {}
+    name, _, ok := embeddedBinary()
+    if !ok {
+        return path, noop, nil
+    }
+    dir, err := embeddedDir()
+    if err != nil {
+        return "", noop, err
+    }
+    if filepath.Clean(path) != filepath.Join(dir, name) {
+        return path, noop, nil
     }
 
-    return dst, nil
+    src, err := os.Open(path)
+    if err != nil {
+        return "", noop, fmt.Errorf("内置 frpc 校验失败: %w", err)
+    }
+    defer src.Close()
+    runDir, err := os.MkdirTemp(dir, ".run-*")
+    if err != nil {
+        return "", noop, fmt.Errorf("内置 frpc 校验失败: %w", err)
+    }
+    runsMu.Lock()
+    activeRuns[runDir] = true
+    runsMu.Unlock()
+    cleanup := func() {
+        _ = os.RemoveAll(runDir)
+        runsMu.Lock()
+        delete(activeRuns, runDir)
+        runsMu.Unlock()
+    }
+    dst := filepath.Join(runDir, name)
+    out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o700)
+    if err != nil {
+        cleanup()
+        return "", noop, fmt.Errorf("内置 frpc 校验失败: %w", err)
+    }
+    h := sha256.New()
+    _, err = io.Copy(io.MultiWriter(out, h), src)
+    if cerr := out.Close(); err == nil {
+        err = cerr
+    }
+    if err != nil {
+        cleanup()
+        return "", noop, fmt.Errorf("内置 frpc 校验失败: %w", err)
+    }
+    want, _ := embeddedSum()
+    if !bytes.Equal(h.Sum(nil), want[:]) {
+        cleanup()
+        log.Error("内置 frpc 校验失败，文件已被修改", "path", path)
+        return "", noop, fmt.Errorf("内置 frpc 校验失败：%s 与内置版本的 SHA-256 不一致，可能已被篡改，已拒绝运行", path)
+    }
+    return dst, cleanup, nil
+}
+
+func removeStaleRuns(dir string) {
+    entries, err := os.ReadDir(dir)
+    if err != nil {
+        return
+    }
+    runsMu.Lock()
+    defer runsMu.Unlock()
+    for _, e := range entries {
+        if !e.IsDir() || !strings.HasPrefix(e.Name(), ".run-") || activeRuns[filepath.Join(dir, e.Name())] {
+            continue
+        }
+        if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > time.Hour {
+            _ = os.RemoveAll(filepath.Join(dir, e.Name()))
+        }
+    }
+}
+
+func removeLegacyBinary(name string) {
+    dir, err := config.CacheDir()
+    if err != nil {
+        return
+    }
+    legacy := filepath.Join(dir, "bin", name)
+    if st, err := os.Stat(legacy); err == nil && !st.IsDir() {
+        _ = os.Remove(legacy)
+    }
 }
 
 func writeIfChanged(path string, data []byte) error {
     existing, err := os.ReadFile(path)
     if err == nil {
         if sha256.Sum256(existing) == sha256.Sum256(data) {
-            return nil
+            return os.Chmod(path, 0o700)
         }
+        log.Warn("已释放的内置 frpc 与当前版本不一致，重新释放", "path", path)
     }
-    if err := os.WriteFile(path, data, 0o700); err != nil {
+    tmp, err := os.CreateTemp(filepath.Dir(path), ".frpc-*")
+    if err != nil {
+        return fmt.Errorf("write embedded frpc: %w", err)
+    }
+    defer os.Remove(tmp.Name())
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return fmt.Errorf("write embedded frpc: %w", err)
+    }
+    if err := tmp.Close(); err != nil {
+        return fmt.Errorf("write embedded frpc: %w", err)
+    }
+    if err := os.Chmod(tmp.Name(), 0o700); err != nil {
+        return fmt.Errorf("write embedded frpc: %w", err)
+    }
+    if err := os.Rename(tmp.Name(), path); err != nil {
         return fmt.Errorf("write embedded frpc: %w", err)
     }
     return nil
 }
+
+func BinaryVersion(path string) (string, error) {
+    run, cleanup, err := stageBinary(path)
+    if err != nil {
+        return "", err
+    }
+    defer cleanup()
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    out, err := exec.CommandContext(ctx, run, "--version").CombinedOutput()
+    if err != nil {
+        return "", fmt.Errorf("获取 frpc 版本失败: %w", err)
+    }
+    return strings.TrimSpace(string(out)), nil
+}
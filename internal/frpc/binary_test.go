@@ -0,0 +1,76 @@
+package frpc
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "runtime"
+    "testing"
+    "time"
+
+    "frpcx/internal/config"
+)
+
+func TestRemoveStaleRunsKeepsActive(t *testing.T) {
+    dir := t.TempDir()
+    old := time.Now().Add(-2 * time.Hour)
+    for _, name := range []string{".run-stale", ".run-active", ".run-fresh"} {
+        if err := os.Mkdir(filepath.Join(dir, name), 0o700); err != nil {
+            t.Fatal(err)
+        }
+    }
+    _ = os.Chtimes(filepath.Join(dir, ".run-stale"), old, old)
+    _ = os.Chtimes(filepath.Join(dir, ".run-active"), old, old)
+
+    active := filepath.Join(dir, ".run-active")
+    runsMu.Lock()
+    activeRuns[active] = true
+    runsMu.Unlock()
+    defer func() {
+        runsMu.Lock()
+        delete(activeRuns, active)
+        runsMu.Unlock()
+    }()
+
+    removeStaleRuns(dir)
+    for name, keep := range map[string]bool{".run-stale": false, ".run-active": true, ".run-fresh": true} {
+        _, err := os.Stat(filepath.Join(dir, name))
+        if (err == nil) != keep {
+            t.Errorf("%s: exists = %v, want %v", name, err == nil, keep)
+        }
+    }
+}
+
+func TestFrpcBackendUsesStagedBinary(t *testing.T) {
+    if runtime.GOOS == "windows" {
+        t.Skip("uses sh")
+    }
+    dir := t.TempDir()
+    marker := filepath.Join(dir, "calls")
+    run := filepath.Join(dir, "frpc")
+    script := "#!/bin/sh\necho \"$@\" >> " + marker + "\n"
+    if err := os.WriteFile(run, []byte(script), 0o700); err != nil {
+        t.Fatal(err)
+    }
+    released := 0
+    b := &frpcBackend{
+        profile:  &config.Profile{Name: "home"},
+        frpcPath: filepath.Join(dir, "missing-original"),
+        run:      run,
+        runCfg:   "frpc.toml",
+        release:  func() { released++ },
+    }
+    for i := 0; i < 3; i++ {
+        if err := b.Health(context.Background()); err != nil {
+            t.Fatal(err)
+        }
+    }
+    calls, _ := os.ReadFile(marker)
+    if string(calls) != "status -c frpc.toml\nstatus -c frpc.toml\nstatus -c frpc.toml\n" {
+        t.Fatalf("calls = %q", calls)
+    }
+    b.Stop(time.Second)
+    if released != 1 {
+        t.Fatalf("released = %d, want 1", released)
+    }
+}
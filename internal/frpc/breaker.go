@@ -0,0 +1,98 @@
+package frpc
+
+import (
+    "sort"
+    "time"
+)
+
+const (
+    CircuitClosed   = "closed"
+    CircuitOpen     = "open"
+    CircuitHalfOpen = "half_open"
+)
+
+type Circuit struct {
+    Profile   string
+    State     string
+    Failures  int
+    Trips     int
+    OpenUntil time.Time
+    LastError string
+}
+
+func (m *Manager) Circuits() []Circuit {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.circuitsLocked()
+}
+
+func (m *Manager) circuitsLocked() []Circuit {
+    out := make([]Circuit, 0, len(m.circuits))
+    for _, c := range m.circuits {
+        out = append(out, *c)
+    }
+    sort.Slice(out, func(i, j int) bool { return out[i].Profile < out[j].Profile })
+    return out
+}
+
+func (m *Manager) circuitLocked(profile string) *Circuit {
+    c, ok := m.circuits[profile]
+    if !ok {
+        c = &Circuit{Profile: profile, State: CircuitClosed}
+        m.circuits[profile] = c
+    }
+    return c
+}
+
+func (m *Manager) allowProfile(profile string) (bool, time.Duration) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    c := m.circuitLocked(profile)
+    if c.State != CircuitOpen {
+        return true, 0
+    }
+    if wait := time.Until(c.OpenUntil); wait > 0 {
+        return false, wait
+    }
+    c.State = CircuitHalfOpen
+    log.Info("熔断冷却结束，尝试恢复", "profile", profile)
+    return true, 0
+}
+
+func (m *Manager) recordSuccess(profile string) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    c := m.circuitLocked(profile)
+    if c.State != CircuitClosed {
+        log.Info("熔断已关闭", "profile", profile)
+    }
+    *c = Circuit{Profile: profile, State: CircuitClosed}
+}
+
+func (m *Manager) recordFailure(profile, msg string) {
+    if profile == "" {
+        return
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    bc := m.cfg.Breaker
+    c := m.circuitLocked(profile)
+    c.Failures++
+    c.LastError = msg
+    if c.State != CircuitHalfOpen && c.Failures < defaultInt(bc.Threshold, 3) {
+        return
+    }
+    base := time.Duration(defaultInt(bc.CooldownSec, 30)) * time.Second
+    limit := time.Duration(defaultInt(bc.MaxCooldownSec, 600)) * time.Second
+    cooldown := base
+    for i := 0; i < c.Trips && cooldown < limit; i++ {
+        cooldown *= 2
+    }
+    if cooldown > limit {
+        cooldown = limit
+    }
+    c.Trips++
+    c.State = CircuitOpen
+    c.OpenUntil = time.Now().Add(cooldown)
+    log.Warn("连续失败，暂时跳过该配置", "profile", profile, "failures", c.Failures, "cooldown", cooldown)
+}
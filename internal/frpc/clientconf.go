@@ -0,0 +1,154 @@
+package frpc
+
+import (
+    "bytes"
+    "fmt"
+    "os"
+    "strings"
+    "text/template"
+
+    "github.com/BurntSushi/toml"
+)
+
+type ProxyInfo struct {
+    Name          string   `toml:"name"`
+    Type          string   `toml:"type"`
+    LocalIP       string   `toml:"localIP"`
+    LocalPort     int      `toml:"localPort"`
+    RemotePort    int      `toml:"remotePort"`
+    CustomDomains []string `toml:"customDomains"`
+    Subdomain     string   `toml:"subdomain"`
+}
+
+type ClientInfo struct {
+    ServerAddr        string
+    ServerPort        int
+    User              string
+    WebServerAddr     string
+    WebServerPort     int
+    WebServerUser     string
+    WebServerPassword string
+    ProxyURL          string
+    AuthMethod        string
+    OIDC              OIDCOptions
+    Transport         TransportOptions
+    Proxies           []ProxyInfo
+    Visitors          []VisitorInfo
+}
+
+type clientFile struct {
+    ServerAddr string `toml:"serverAddr"`
+    ServerPort int    `toml:"serverPort"`
+    User       string `toml:"user"`
+    Auth       struct {
+        Method string      `toml:"method"`
+        OIDC   OIDCOptions `toml:"oidc"`
+    } `toml:"auth"`
+    WebServer struct {
+        Addr     string `toml:"addr"`
+        Port     int    `toml:"port"`
+        User     string `toml:"user"`
+        Password string `toml:"password"`
+    } `toml:"webServer"`
+    Transport struct {
+        ProxyURL          string `toml:"proxyURL"`
+        Protocol          string `toml:"protocol"`
+        TCPMux            *bool  `toml:"tcpMux"`
+        PoolCount         int    `toml:"poolCount"`
+        HeartbeatInterval int    `toml:"heartbeatInterval"`
+        HeartbeatTimeout  int    `toml:"heartbeatTimeout"`
+        TLS               struct {
+            Enable        *bool  `toml:"enable"`
+            CertFile      string `toml:"certFile"`
+            KeyFile       string `toml:"keyFile"`
+            TrustedCaFile string `toml:"trustedCaFile"`
+            ServerName    string `toml:"serverName"`
+        } `toml:"tls"`
+    } `toml:"transport"`
+    Proxies  []ProxyInfo   `toml:"proxies"`
+    Visitors []VisitorInfo `toml:"visitors"`
+}
+
+func LoadClientInfo(path string) (*ClientInfo, error) {
+    if f, err := configFormat(path); err != nil {
+        return nil, err
+    } else if f != "toml" {
+        return nil, fmt.Errorf("暂不支持读取 %s 配置", strings.ToUpper(f))
+    }
+    var f clientFile
+    if _, err := decodeTomlFile(path, &f); err != nil {
+        return nil, err
+    }
+
+    info := &ClientInfo{
+        ServerAddr:        f.ServerAddr,
+        ServerPort:        f.ServerPort,
+        User:              f.User,
+        WebServerAddr:     f.WebServer.Addr,
+        WebServerPort:     f.WebServer.Port,
+        WebServerUser:     f.WebServer.User,
+        WebServerPassword: f.WebServer.Password,
+        ProxyURL:          f.Transport.ProxyURL,
+        AuthMethod:        f.Auth.Method,
+        OIDC:              f.Auth.OIDC,
+        Transport:         DefaultTransport(),
+        Proxies:           f.Proxies,
+        Visitors:          f.Visitors,
+    }
+    t := &info.Transport
+    if f.Transport.Protocol != "" {
+        t.Protocol = f.Transport.Protocol
+    }
+    if f.Transport.TLS.Enable != nil {
+        t.TLSEnable = *f.Transport.TLS.Enable
+    }
+    if f.Transport.TCPMux != nil {
+        t.TCPMux = *f.Transport.TCPMux
+    }
+    t.CertFile = f.Transport.TLS.CertFile
+    t.KeyFile = f.Transport.TLS.KeyFile
+    t.TrustedCaFile = f.Transport.TLS.TrustedCaFile
+    t.ServerName = f.Transport.TLS.ServerName
+    t.PoolCount = f.Transport.PoolCount
+    t.HeartbeatInterval = f.Transport.HeartbeatInterval
+    t.HeartbeatTimeout = f.Transport.HeartbeatTimeout
+    for i := range info.Proxies {
+        if info.Proxies[i].LocalIP == "" {
+            info.Proxies[i].LocalIP = "127.0.0.1"
+        }
+    }
+    return info, nil
+}
+
+func decodeTomlFile(path string, v any) (toml.MetaData, error) {
+    b, err := os.ReadFile(path)
+    if err != nil {
+        return toml.MetaData{}, err
+    }
+    md, err := toml.Decode(string(b), v)
+    if err == nil || !bytes.Contains(b, []byte("{{")) {
+        return md, err
+    }
+    rendered, rerr := renderEnvTemplate(b)
+    if rerr != nil {
+        return md, err
+    }
+    return toml.Decode(rendered, v)
+}
+
+func renderEnvTemplate(b []byte) (string, error) {
+    t, err := template.New("frpc").Parse(string(b))
+    if err != nil {
+        return "", err
+    }
+    envs := map[string]string{}
+    for _, kv := range os.Environ() {
+        k, v, _ := strings.Cut(kv, "=")
+        envs[k] = v
+    }
+    var out strings.Builder
+    if err := t.Execute(&out, struct{ Envs map[string]string }{envs}); err != nil {
+        return "", err
+    }
+    return out.String(), nil
+}
@@ -0,0 +1,111 @@
+package frpc
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+)
+
+func writeTemp(t *testing.T, name, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), name)
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestLoadClientInfo(t *testing.T) {
+    path := writeTemp(t, "frpc.toml", `
+serverAddr = "frps.example.com" # 注释
+serverPort = 7000
+auth = { method = "oidc", oidc = { clientID = "cid", tokenEndpointURL = "https://idp/token" } }
+
+[webServer]
+addr = "127.0.0.1"
+port = 7400
+password = "p#ss"
+
+[transport]
+protocol = "wss"
+tls.enable = false
+tcpMux = false
+poolCount = 5
+
+[[proxies]]
+name = "web"
+type = "http"
+localPort = 8080
+customDomains = [
+    "a.example.com", # 第一个
+    "b.example.com",
+]
+
+[[visitors]]
+name = "db"
+type = "stcp"
+serverName = "alice-db"
+secretKey = "k#1"
+bindPort = 9000
+`)
+    info, err := LoadClientInfo(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if info.ServerAddr != "frps.example.com" || info.ServerPort != 7000 {
+        t.Fatalf("server = %s:%d", info.ServerAddr, info.ServerPort)
+    }
+    if info.AuthMethod != AuthOIDC || info.OIDC.ClientID != "cid" || info.OIDC.TokenEndpointURL != "https://idp/token" {
+        t.Fatalf("auth = %q %+v", info.AuthMethod, info.OIDC)
+    }
+    if info.WebServerPort != 7400 || info.WebServerPassword != "p#ss" {
+        t.Fatalf("webServer = %d %q", info.WebServerPort, info.WebServerPassword)
+    }
+    want := TransportOptions{Protocol: "wss", PoolCount: 5}
+    if info.Transport != want {
+        t.Fatalf("transport = %+v", info.Transport)
+    }
+    if len(info.Proxies) != 1 || !reflect.DeepEqual(info.Proxies[0].CustomDomains, []string{"a.example.com", "b.example.com"}) || info.Proxies[0].LocalIP != "127.0.0.1" {
+        t.Fatalf("proxies = %+v", info.Proxies)
+    }
+    if len(info.Visitors) != 1 || info.Visitors[0].SecretKey != "k#1" || info.Visitors[0].BindPort != 9000 {
+        t.Fatalf("visitors = %+v", info.Visitors)
+    }
+}
+
+func TestLoadClientInfoDefaults(t *testing.T) {
+    info, err := LoadClientInfo(writeTemp(t, "frpc.toml", `serverAddr = "x"`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !info.Transport.IsDefault() {
+        t.Fatalf("transport = %+v", info.Transport)
+    }
+}
+
+func TestLoadClientInfoEnvTemplate(t *testing.T) {
+    t.Setenv("FRPCX_TEST_PORT", "7100")
+    path := writeTemp(t, "frpc.toml", "serverAddr = \"x\"\nserverPort = {{ .Envs.FRPCX_TEST_PORT }}\nauth.oidc.clientSecret = \"{{ .Envs.FRP_OIDC_CLIENT_SECRET }}\"\n")
+    info, err := LoadClientInfo(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if info.ServerPort != 7100 {
+        t.Fatalf("serverPort = %d", info.ServerPort)
+    }
+
+    info, err = LoadClientInfo(writeTemp(t, "frpc.toml", "auth.oidc.clientSecret = \""+OIDCSecretRef()+"\"\n"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if info.OIDC.ClientSecret != OIDCSecretRef() {
+        t.Fatalf("clientSecret = %q", info.OIDC.ClientSecret)
+    }
+}
+
+func TestLoadClientInfoRejectsIni(t *testing.T) {
+    if _, err := LoadClientInfo(writeTemp(t, "frpc.ini", "[common]\nserver_addr = x\n")); err == nil {
+        t.Fatal("expected error for INI config")
+    }
+}
@@ -0,0 +1,456 @@
+package frpc
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+
+    "frpcx/internal/config"
+    "frpcx/internal/logs"
+)
+
+const minTomlVersion = "0.52.0"
+
+type Compat struct {
+    Version  string
+    Format   string
+    NeedIni  bool
+    Warnings []string
+}
+
+type IncompatibleError struct {
+    Version string
+    Format  string
+}
+
+func (e *IncompatibleError) Error() string {
+    return fmt.Sprintf("frpc %s 不支持 %s 配置（需要 %s 及以上），请升级 frpc 或改用 INI 配置", e.Version, strings.ToUpper(e.Format), minTomlVersion)
+}
+
+type cachedVersion struct {
+    size    int64
+    modTime int64
+    version string
+}
+
+var (
+    versionCacheMu sync.Mutex
+    versionCache   = map[string]cachedVersion{}
+)
+
+var featureVersions = []struct {
+    key     string
+    version string
+}{
+    {"featureGates", "0.62.0"},
+    {"virtualNet", "0.62.0"},
+    {"proxies.plugin.type=virtual_net", "0.62.0"},
+    {"visitors.plugin.type=virtual_net", "0.62.0"},
+}
+
+func DetectVersion(path string) (string, error) {
+    st, err := os.Stat(path)
+    if err != nil {
+        return "", err
+    }
+    versionCacheMu.Lock()
+    c, ok := versionCache[path]
+    versionCacheMu.Unlock()
+    if ok && c.size == st.Size() && c.modTime == st.ModTime().UnixNano() {
+        return c.version, nil
+    }
+
+    out, err := BinaryVersion(path)
+    if err != nil {
+        return "", err
+    }
+    v := binaryVersionRe.FindString(out)
+    if v == "" {
+        return "", fmt.Errorf("无法识别 frpc 版本: %s", out)
+    }
+    versionCacheMu.Lock()
+    versionCache[path] = cachedVersion{size: st.Size(), modTime: st.ModTime().UnixNano(), version: v}
+    versionCacheMu.Unlock()
+    log.Debug("检测到 frpc 版本", "path", path, "version", v)
+    return v, nil
+}
+
+func ProfileVersion(p *config.Profile) (string, error) {
+    if UsesLibrary(p) {
+        return libraryVersion(), nil
+    }
+    path, err := ResolveProfileBinary(p)
+    if err != nil {
+        return "", err
+    }
+    return DetectVersion(path)
+}
+
+func CheckProfileCompat(p *config.Profile) (Compat, error) {
+    cfgPath, err := resolveConfigPath(p)
+    if err != nil {
+        return Compat{}, err
+    }
+    if UsesLibrary(p) {
+        format, err := configFormat(cfgPath)
+        return Compat{Version: libraryVersion(), Format: format}, err
+    }
+    frpcPath, err := ResolveProfileBinary(p)
+    if err != nil {
+        return Compat{}, err
+    }
+    return CheckCompat(frpcPath, cfgPath)
+}
+
+func CheckCompat(frpcPath, cfgPath string) (Compat, error) {
+    version, err := DetectVersion(frpcPath)
+    if err != nil {
+        return Compat{}, err
+    }
+    format, err := configFormat(cfgPath)
+    if err != nil {
+        return Compat{}, err
+    }
+    c := Compat{Version: version, Format: format}
+    if format == "ini" {
+        return c, nil
+    }
+    if compareVersions(version, minTomlVersion) < 0 {
+        c.NeedIni = true
+        if format != "toml" {
+            return c, &IncompatibleError{Version: version, Format: format}
+        }
+        return c, nil
+    }
+    if format != "toml" {
+        return c, nil
+    }
+
+    common, sections, err := readTomlFile(cfgPath)
+    if err != nil {
+        return c, err
+    }
+    keys := map[string]bool{}
+    for _, kv := range common {
+        keys[kv.key] = true
+        keys[firstKey(kv.key)] = true
+    }
+    for _, s := range sections {
+        for _, kv := range s.entries {
+            keys[s.kind+"."+kv.key] = true
+            keys[s.kind+"."+kv.key+"="+iniValue(kv.value)] = true
+        }
+    }
+    for _, f := range featureVersions {
+        if keys[f.key] && compareVersions(version, f.version) < 0 {
+            c.Warnings = append(c.Warnings, fmt.Sprintf("%s 需要 frpc %s 及以上，当前为 %s", f.key, f.version, version))
+        }
+    }
+    return c, nil
+}
+
+func prepareConfig(frpcPath, cfgPath string, p *config.Profile) (string, error) {
+    if frpcPath == "" {
+        return cfgPath, nil
+    }
+    c, err := CheckCompat(frpcPath, cfgPath)
+    if err != nil {
+        if _, ok := err.(*IncompatibleError); ok {
+            return "", err
+        }
+        log.Warn("frpc 兼容性检查失败", "profile", p.Name, "err", err)
+        return cfgPath, nil
+    }
+    for _, w := range c.Warnings {
+        log.Warn("配置项可能不受支持", "profile", p.Name, "version", c.Version, "detail", w)
+    }
+    if !c.NeedIni {
+        return cfgPath, nil
+    }
+    if !p.ConvertToIni {
+        return "", &IncompatibleError{Version: c.Version, Format: c.Format}
+    }
+
+    dir, err := config.CacheDir()
+    if err != nil {
+        return "", err
+    }
+    iniPath := filepath.Join(dir, "ini", logs.SafeName(p.Name)+".ini")
+    warnings, err := ConvertToIni(cfgPath, iniPath)
+    if err != nil {
+        return "", fmt.Errorf("生成 INI 配置失败: %w", err)
+    }
+    for _, w := range warnings {
+        log.Warn("INI 配置不支持该选项，已忽略", "profile", p.Name, "version", c.Version, "key", w)
+    }
+    log.Info("已生成 INI 配置", "profile", p.Name, "version", c.Version, "path", iniPath)
+    return iniPath, nil
+}
+
+func configFormat(path string) (string, error) {
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".ini":
+        return "ini", nil
+    case ".toml":
+        return "toml", nil
+    case ".yaml", ".yml":
+        return "yaml", nil
+    case ".json":
+        return "json", nil
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return "", err
+    }
+    if strings.Contains(string(data), "[common]") {
+        return "ini", nil
+    }
+    return "toml", nil
+}
+
+type tomlKV struct {
+    key   string
+    value any
+}
+
+type tomlSection struct {
+    kind    string
+    entries []tomlKV
+}
+
+func readTomlFile(path string) ([]tomlKV, []tomlSection, error) {
+    var m map[string]any
+    md, err := decodeTomlFile(path, &m)
+    if err != nil {
+        return nil, nil, err
+    }
+    order := map[string]int{}
+    for i, k := range md.Keys() {
+        if _, ok := order[k.String()]; !ok {
+            order[k.String()] = i
+        }
+    }
+    byOrder := func(prefix string, kvs []tomlKV) {
+        sort.SliceStable(kvs, func(i, j int) bool {
+            oi, iok := order[prefix+kvs[i].key]
+            oj, jok := order[prefix+kvs[j].key]
+            if iok != jok {
+                return iok
+            }
+            if oi != oj {
+                return oi < oj
+            }
+            return kvs[i].key < kvs[j].key
+        })
+    }
+
+    var common []tomlKV
+    var sections []tomlSection
+    var kinds []string
+    for k, v := range m {
+        if _, ok := v.([]map[string]any); ok {
+            kinds = append(kinds, k)
+            continue
+        }
+        flattenToml(k, v, &common)
+    }
+    byOrder("", common)
+    sort.Slice(kinds, func(i, j int) bool { return order[kinds[i]] < order[kinds[j]] })
+    for _, kind := range kinds {
+        for _, t := range m[kind].([]map[string]any) {
+            s := tomlSection{kind: kind}
+            for k, v := range t {
+                flattenToml(k, v, &s.entries)
+            }
+            byOrder(kind+".", s.entries)
+            sections = append(sections, s)
+        }
+    }
+    return common, sections, nil
+}
+
+func flattenToml(key string, v any, out *[]tomlKV) {
+    if sub, ok := v.(map[string]any); ok {
+        for k, vv := range sub {
+            flattenToml(key+"."+k, vv, out)
+        }
+        return
+    }
+    *out = append(*out, tomlKV{key: key, value: v})
+}
+
+var iniCommonKeys = map[string]string{
+    "serverAddr":                        "server_addr",
+    "serverPort":                        "server_port",
+    "user":                              "user",
+    "loginFailExit":                     "login_fail_exit",
+    "dnsServer":                         "dns_server",
+    "udpPacketSize":                     "udp_packet_size",
+    "natHoleStunServer":                 "nat_hole_stun_server",
+    "auth.method":                       "authentication_method",
+    "auth.token":                        "token",
+    "auth.oidc.clientID":                "oidc_client_id",
+    "auth.oidc.clientSecret":            "oidc_client_secret",
+    "auth.oidc.audience":                "oidc_audience",
+    "auth.oidc.scope":                   "oidc_scope",
+    "auth.oidc.tokenEndpointURL":        "oidc_token_endpoint_url",
+    "webServer.addr":                    "admin_addr",
+    "webServer.port":                    "admin_port",
+    "webServer.user":                    "admin_user",
+    "webServer.password":                "admin_pwd",
+    "transport.protocol":                "protocol",
+    "transport.tcpMux":                  "tcp_mux",
+    "transport.tcpMuxKeepaliveInterval": "tcp_mux_keepalive_interval",
+    "transport.poolCount":               "pool_count",
+    "transport.dialServerTimeout":       "dial_server_timeout",
+    "transport.dialServerKeepalive":     "dial_server_keepalive",
+    "transport.connectServerLocalIP":    "connect_server_local_ip",
+    "transport.proxyURL":                "http_proxy",
+    "transport.heartbeatInterval":       "heartbeat_interval",
+    "transport.heartbeatTimeout":        "heartbeat_timeout",
+    "transport.tls.enable":              "tls_enable",
+    "transport.tls.certFile":            "tls_cert_file",
+    "transport.tls.keyFile":             "tls_key_file",
+    "transport.tls.trustedCaFile":       "tls_trusted_ca_file",
+    "transport.tls.serverName":          "tls_server_name",
+    "log.to":                            "log_file",
+    "log.level":                         "log_level",
+    "log.maxDays":                       "log_max_days",
+    "log.disablePrintColor":             "disable_log_color",
+}
+
+var iniProxyKeys = map[string]string{
+    "type":                           "type",
+    "localIP":                        "local_ip",
+    "localPort":                      "local_port",
+    "remotePort":                     "remote_port",
+    "customDomains":                  "custom_domains",
+    "subdomain":                      "subdomain",
+    "locations":                      "locations",
+    "hostHeaderRewrite":              "host_header_rewrite",
+    "httpUser":                       "http_user",
+    "httpPassword":                   "http_pwd",
+    "routeByHTTPUser":                "route_by_http_user",
+    "multiplexer":                    "multiplexer",
+    "secretKey":                      "sk",
+    "allowUsers":                     "allow_users",
+    "serverName":                     "server_name",
+    "serverUser":                     "server_user",
+    "bindAddr":                       "bind_addr",
+    "bindPort":                       "bind_port",
+    "keepTunnelOpen":                 "keep_tunnel_open",
+    "transport.useEncryption":        "use_encryption",
+    "transport.useCompression":       "use_compression",
+    "transport.bandwidthLimit":       "bandwidth_limit",
+    "transport.bandwidthLimitMode":   "bandwidth_limit_mode",
+    "transport.proxyProtocolVersion": "proxy_protocol_version",
+    "loadBalancer.group":             "group",
+    "loadBalancer.groupKey":          "group_key",
+    "healthCheck.type":               "health_check_type",
+    "healthCheck.timeoutSeconds":     "health_check_timeout_s",
+    "healthCheck.maxFailed":          "health_check_max_failed",
+    "healthCheck.intervalSeconds":    "health_check_interval_s",
+    "healthCheck.path":               "health_check_url",
+    "plugin.type":                    "plugin",
+    "plugin.localAddr":               "plugin_local_addr",
+    "plugin.localPath":               "plugin_local_path",
+    "plugin.stripPrefix":             "plugin_strip_prefix",
+    "plugin.httpUser":                "plugin_http_user",
+    "plugin.httpPassword":            "plugin_http_passwd",
+    "plugin.unixPath":                "plugin_unix_path",
+    "plugin.username":                "plugin_user",
+    "plugin.password":                "plugin_passwd",
+    "plugin.crtPath":                 "plugin_crt_path",
+    "plugin.keyPath":                 "plugin_key_path",
+    "plugin.hostHeaderRewrite":       "plugin_host_header_rewrite",
+}
+
+func ConvertToIni(tomlPath, iniPath string) ([]string, error) {
+    common, sections, err := readTomlFile(tomlPath)
+    if err != nil {
+        return nil, err
+    }
+
+    var b strings.Builder
+    var skipped []string
+    b.WriteString("# 由 frpcx 根据 " + filepath.Base(tomlPath) + " 自动生成，请勿手动修改\n[common]\n")
+    for _, kv := range common {
+        switch kv.key {
+        case "auth.additionalScopes":
+            for _, scope := range strings.Split(iniValue(kv.value), ",") {
+                switch scope {
+                case "HeartBeats":
+                    b.WriteString("authenticate_heartbeats = true\n")
+                case "NewWorkConns":
+                    b.WriteString("authenticate_new_work_conns = true\n")
+                }
+            }
+            continue
+        }
+        name, ok := iniCommonKeys[kv.key]
+        if !ok {
+            skipped = append(skipped, kv.key)
+            continue
+        }
+        b.WriteString(name + " = " + iniValue(kv.value) + "\n")
+    }
+
+    for i, s := range sections {
+        if s.kind != "proxies" && s.kind != "visitors" {
+            skipped = append(skipped, s.kind)
+            continue
+        }
+        name := ""
+        for _, kv := range s.entries {
+            if kv.key == "name" {
+                name = iniValue(kv.value)
+            }
+        }
+        if name == "" {
+            name = fmt.Sprintf("%s_%d", s.kind, i)
+        }
+        b.WriteString("\n[" + name + "]\n")
+        if s.kind == "visitors" {
+            b.WriteString("role = visitor\n")
+        }
+        for _, kv := range s.entries {
+            if kv.key == "name" {
+                continue
+            }
+            key, ok := iniProxyKeys[kv.key]
+            if !ok {
+                skipped = append(skipped, s.kind+"."+kv.key)
+                continue
+            }
+            b.WriteString(key + " = " + iniValue(kv.value) + "\n")
+        }
+    }
+
+    if err := os.MkdirAll(filepath.Dir(iniPath), 0o700); err != nil {
+        return nil, err
+    }
+    if err := os.WriteFile(iniPath, []byte(b.String()), 0o600); err != nil {
+        return nil, err
+    }
+    sort.Strings(skipped)
+    return skipped, nil
+}
+
+func iniValue(v any) string {
+    if list, ok := v.([]any); ok {
+        items := make([]string, 0, len(list))
+        for _, item := range list {
+            items = append(items, fmt.Sprint(item))
+        }
+        return strings.Join(items, ",")
+    }
+    return fmt.Sprint(v)
+}
+
+func firstKey(key string) string {
+    k, _, _ := strings.Cut(key, ".")
+    return k
+}
@@ -0,0 +1,47 @@
+package frpc
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestConvertToIni(t *testing.T) {
+    src := writeTemp(t, "frpc.toml", `
+serverAddr = "x"
+serverPort = 7000
+auth.token = "t#1"
+auth.additionalScopes = ["HeartBeats"]
+metadatas = { team = "a" }
+
+[[proxies]]
+name = "web"
+type = "http"
+localPort = 8080
+customDomains = [
+    "a.example.com",
+    "b.example.com",
+]
+transport = { useEncryption = true }
+`)
+    out := filepath.Join(t.TempDir(), "frpc.ini")
+    skipped, err := ConvertToIni(src, out)
+    if err != nil {
+        t.Fatal(err)
+    }
+    b, _ := os.ReadFile(out)
+    got := string(b)
+    for _, want := range []string{
+        "server_addr = x\nserver_port = 7000\ntoken = t#1\nauthenticate_heartbeats = true\n",
+        "[web]\ntype = http\nlocal_port = 8080\ncustom_domains = a.example.com,b.example.com\nuse_encryption = true\n",
+    } {
+        if !strings.Contains(got, want) {
+            t.Fatalf("missing %q in\n%s", want, got)
+        }
+    }
+    if !reflect.DeepEqual(skipped, []string{"metadatas.team"}) {
+        t.Fatalf("skipped = %v", skipped)
+    }
+}
@@ -0,0 +1,60 @@
+package frpc
+
+import "time"
+
+type EventType string
+
+const (
+    EventReady     EventType = "ready"
+    EventFailed    EventType = "failed"
+    EventFailover  EventType = "failover"
+    EventFailback  EventType = "failback"
+    EventDegraded  EventType = "degraded"
+    EventRecovered EventType = "recovered"
+    EventStopped   EventType = "stopped"
+)
+
+type Event struct {
+    Type    EventType
+    Time    time.Time
+    Profile string
+    From    string
+    Message string
+}
+
+func (m *Manager) Subscribe(fn func(Event)) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.subscribers = append(m.subscribers, fn)
+}
+
+func (m *Manager) emit(e Event) {
+    if e.Time.IsZero() {
+        e.Time = time.Now()
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.events = append(m.events, e)
+    if !m.dispatching {
+        m.dispatching = true
+        go m.dispatch()
+    }
+}
+
+func (m *Manager) dispatch() {
+    for {
+        m.mu.Lock()
+        if len(m.events) == 0 {
+            m.dispatching = false
+            m.mu.Unlock()
+            return
+        }
+        e := m.events[0]
+        m.events = m.events[1:]
+        subs := append([]func(Event){}, m.subscribers...)
+        m.mu.Unlock()
+        for _, fn := range subs {
+            fn(e)
+        }
+    }
+}
@@ -0,0 +1,125 @@
+package frpc
+
+import (
+    "context"
+    "time"
+
+    "frpcx/internal/config"
+)
+
+const maxFailbackPenalty = 4
+
+func (m *Manager) watchFailback(ctx context.Context, b Backend, current string) {
+    m.mu.Lock()
+    interval := time.Duration(defaultInt(m.cfg.FailBack.IntervalSec, 30)) * time.Second
+    m.mu.Unlock()
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    since := time.Now()
+    healthy := map[string]time.Time{}
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+
+        m.mu.Lock()
+        fb := m.cfg.FailBack
+        ok := fb.Enabled && m.autoSwitch && (m.strategy == "" || m.strategy == StrategyOrdered) &&
+            m.status == "running" && m.backend == b
+        profiles := enabledProfiles(m.cfg.Profiles)
+        active := m.cfg.ActiveProfile
+        penalty := map[string]uint{}
+        for k, v := range m.penalty {
+            penalty[k] = v
+        }
+        open := map[string]bool{}
+        for k, c := range m.circuits {
+            open[k] = c.State == CircuitOpen && time.Now().Before(c.OpenUntil)
+        }
+        m.mu.Unlock()
+        if !ok {
+            healthy = map[string]time.Time{}
+            continue
+        }
+
+        above := preferredAbove(profiles, active, current)
+        if len(above) == 0 {
+            return
+        }
+
+        now := time.Now()
+        target := -1
+        for _, i := range above {
+            p := profiles[i]
+            if open[p.Name] {
+                delete(healthy, p.Name)
+                continue
+            }
+            l := probe(ctx, &p)
+            if !l.OK() {
+                if _, seen := healthy[p.Name]; seen {
+                    log.Debug("首选配置探测失败", "profile", p.Name, "err", l.Err)
+                }
+                delete(healthy, p.Name)
+                continue
+            }
+            first, seen := healthy[p.Name]
+            if !seen {
+                log.Info("首选配置已可达，等待稳定", "profile", p.Name, "rtt", l.RTT)
+                healthy[p.Name] = now
+                first = now
+            }
+            stable := time.Duration(defaultInt(fb.StableSec, 120)) * time.Second << penalty[p.Name]
+            if target < 0 && now.Sub(first) >= stable {
+                target = i
+            }
+        }
+        hold := time.Duration(defaultInt(fb.HoldSec, 300)) * time.Second
+        if target < 0 || now.Sub(since) < hold {
+            continue
+        }
+        log.Info("首选配置已稳定", "profile", profiles[target].Name, "current", current)
+        m.post(command{kind: cmdFailback, backend: b, name: profiles[target].Name, index: target})
+        return
+    }
+}
+
+func preferredAbove(profiles []config.Profile, active, current string) []int {
+    n := len(profiles)
+    start := 0
+    for i, p := range profiles {
+        if p.Name == active {
+            start = i
+            break
+        }
+    }
+    var out []int
+    for i := 0; i < n; i++ {
+        idx := (start + i) % n
+        if profiles[idx].Name == current {
+            return out
+        }
+        out = append(out, idx)
+    }
+    return nil
+}
+
+func (m *Manager) penalizeFailback(name string) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if name == "" || name != m.failbackTo {
+        return
+    }
+    hold := time.Duration(defaultInt(m.cfg.FailBack.HoldSec, 300)) * time.Second
+    if time.Since(m.failbackAt) > hold {
+        return
+    }
+    if m.penalty[name] < maxFailbackPenalty {
+        m.penalty[name]++
+    }
+    m.failbackTo = ""
+    log.Warn("切回后很快失败，延长稳定等待时间", "profile", name, "factor", 1<<m.penalty[name])
+}
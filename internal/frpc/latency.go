@@ -0,0 +1,175 @@
+package frpc
+
+import (
+    "context"
+    "errors"
+    "net"
+    "sort"
+    "strconv"
+    "sync"
+    "time"
+
+    "frpcx/internal/config"
+)
+
+const (
+    StrategyOrdered    = "ordered"
+    StrategyLatency    = "latency"
+    StrategyRoundRobin = "round_robin"
+)
+
+type Latency struct {
+    Profile string
+    Addr    string
+    RTT     time.Duration
+    Err     string
+    Time    time.Time
+}
+
+func (l Latency) OK() bool {
+    return l.Err == ""
+}
+
+func (m *Manager) Latencies() []Latency {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return append([]Latency(nil), m.latency...)
+}
+
+func (m *Manager) MeasureLatency() []Latency {
+    return m.measureLatency(context.Background())
+}
+
+func (m *Manager) measureLatency(ctx context.Context) []Latency {
+    m.mu.Lock()
+    profiles := enabledProfiles(m.cfg.Profiles)
+    m.mu.Unlock()
+
+    out := measureLatency(ctx, profiles)
+    m.mu.Lock()
+    m.latency = out
+    m.mu.Unlock()
+    for _, l := range out {
+        if l.OK() {
+            log.Debug("测速", "profile", l.Profile, "addr", l.Addr, "rtt", l.RTT)
+        } else {
+            log.Debug("测速失败", "profile", l.Profile, "addr", l.Addr, "err", l.Err)
+        }
+    }
+    return out
+}
+
+func (m *Manager) candidateOrder(ctx context.Context, profiles []config.Profile, start int, from string) []int {
+    m.mu.Lock()
+    strategy := m.strategy
+    last := m.lastIndex
+    active := m.cfg.ActiveProfile
+    m.mu.Unlock()
+
+    n := len(profiles)
+    rotate := func(idx int) []int {
+        out := make([]int, 0, n)
+        for i := 0; i < n; i++ {
+            out = append(out, (idx+i)%n)
+        }
+        return out
+    }
+
+    switch strategy {
+    case StrategyLatency:
+        results := m.measureLatency(ctx)
+        byName := map[string]Latency{}
+        for _, l := range results {
+            byName[l.Profile] = l
+        }
+        order := rotate(0)
+        sort.SliceStable(order, func(a, b int) bool {
+            pa, pb := profiles[order[a]], profiles[order[b]]
+            if (pa.Name == from) != (pb.Name == from) {
+                return pb.Name == from
+            }
+            la, lb := byName[pa.Name], byName[pb.Name]
+            if la.OK() != lb.OK() {
+                return la.OK()
+            }
+            return la.RTT < lb.RTT
+        })
+        return order
+    case StrategyRoundRobin:
+        if start < 0 {
+            start = last + 1
+        }
+    default:
+        if start < 0 {
+            start = 0
+            for i, p := range profiles {
+                if p.Name == active {
+                    start = i
+                    break
+                }
+            }
+        }
+    }
+    if start < 0 {
+        start = 0
+    }
+    return rotate(start % n)
+}
+
+func measureLatency(ctx context.Context, profiles []config.Profile) []Latency {
+    out := make([]Latency, len(profiles))
+    var wg sync.WaitGroup
+    for i := range profiles {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            out[i] = probe(ctx, &profiles[i])
+        }(i)
+    }
+    wg.Wait()
+    return out
+}
+
+func probe(ctx context.Context, p *config.Profile) Latency {
+    l := Latency{Profile: p.Name, Time: time.Now()}
+    addr, err := serverAddress(p)
+    if err != nil {
+        l.Err = err.Error()
+        return l
+    }
+    l.Addr = addr
+    timeout := time.Duration(defaultInt(p.HealthTimeoutSec, 3)) * time.Second
+    begin := time.Now()
+    conn, err := dialServer(ctx, p, addr, timeout)
+    if err != nil {
+        l.Err = err.Error()
+        return l
+    }
+    l.RTT = time.Since(begin)
+    _ = conn.Close()
+    return l
+}
+
+func serverAddress(p *config.Profile) (string, error) {
+    if backendName(p) == BackendSSH {
+        if p.SSH.Host == "" {
+            return "", errors.New("SSH 主机为空")
+        }
+        return net.JoinHostPort(p.SSH.Host, strconv.Itoa(defaultInt(p.SSH.Port, 22))), nil
+    }
+    if p.ServerAddr != "" && p.ServerPort > 0 {
+        return net.JoinHostPort(p.ServerAddr, strconv.Itoa(p.ServerPort)), nil
+    }
+    cfgPath, err := resolveConfigPath(p)
+    if err != nil {
+        return "", err
+    }
+    info, err := LoadClientInfo(cfgPath)
+    if err != nil {
+        return "", err
+    }
+    if info.ServerAddr == "" {
+        return "", errors.New("配置中没有 serverAddr")
+    }
+    return net.JoinHostPort(info.ServerAddr, strconv.Itoa(defaultInt(info.ServerPort, 7000))), nil
+}
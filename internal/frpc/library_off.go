@@ -0,0 +1,15 @@
+//go:build !with_frpc_lib
+
+package frpc
+
+import "errors"
+
+const libraryAvailable = false
+
+func libraryVersion() string {
+    return ""
+}
+
+func startLibrary(cfgPath, oidcSecret, proxyURL string, onLine func(string), onReady func(), onFail func(error)) (runner, error) {
+    return nil, errors.New("当前构建未包含 frp 库")
+}
@@ -0,0 +1,190 @@
+//go:build with_frpc_lib
+
+package frpc
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/fatedier/frp/client"
+    frpconfig "github.com/fatedier/frp/pkg/config"
+    frplog "github.com/fatedier/frp/pkg/util/log"
+    frpversion "github.com/fatedier/frp/pkg/util/version"
+    golog "github.com/fatedier/golib/log"
+)
+
+const libraryAvailable = true
+
+var (
+    libraryMu     sync.Mutex
+    libraryOutput = &libraryWriter{}
+)
+
+func libraryVersion() string {
+    return frpversion.Full()
+}
+
+type libraryWriter struct {
+    mu sync.Mutex
+    w  *lineWriter
+}
+
+func (l *libraryWriter) set(onLine func(string)) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.w = &lineWriter{fn: onLine}
+}
+
+func (l *libraryWriter) Write(b []byte) (int, error) {
+    l.mu.Lock()
+    w := l.w
+    l.mu.Unlock()
+    if w == nil {
+        return len(b), nil
+    }
+    return w.Write(b)
+}
+
+type libraryService struct {
+    svr     *client.Service
+    proxies []string
+    cancel  context.CancelFunc
+    done    chan struct{}
+    err     error
+    once    sync.Once
+}
+
+func startLibrary(cfgPath, oidcSecret, proxyURL string, onLine func(string), onReady func(), onFail func(error)) (runner, error) {
+    if !libraryMu.TryLock() {
+        return nil, errors.New("已有进程内 frpc 正在运行")
+    }
+
+    common, proxyCfgs, visitorCfgs, _, err := frpconfig.LoadClientConfig(cfgPath, true)
+    if err != nil {
+        libraryMu.Unlock()
+        return nil, fmt.Errorf("加载配置失败: %w", err)
+    }
+    if oidcSecret != "" {
+        common.Auth.OIDC.ClientSecret = oidcSecret
+    }
+    if proxyURL != "" {
+        common.Transport.ProxyURL = proxyURL
+    }
+
+    libraryOutput.set(onLine)
+    level, err := golog.ParseLevel(common.Log.Level)
+    if err != nil {
+        level = golog.InfoLevel
+    }
+    frplog.Logger = frplog.Logger.WithOptions(golog.WithOutput(libraryOutput), golog.WithLevel(level))
+
+    svr, err := client.NewService(client.ServiceOptions{
+        Common:         common,
+        ProxyCfgs:      proxyCfgs,
+        VisitorCfgs:    visitorCfgs,
+        ConfigFilePath: cfgPath,
+    })
+    if err != nil {
+        libraryMu.Unlock()
+        return nil, err
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    s := &libraryService{svr: svr, cancel: cancel, done: make(chan struct{})}
+    for _, c := range proxyCfgs {
+        s.proxies = append(s.proxies, c.GetBaseConfig().Name)
+    }
+
+    go func() {
+        defer libraryMu.Unlock()
+        s.err = svr.Run(ctx)
+        if s.err != nil {
+            onFail(s.err)
+        }
+        close(s.done)
+    }()
+    go s.watchReady(ctx, onReady, onFail)
+    return s, nil
+}
+
+func (s *libraryService) watchReady(ctx context.Context, onReady func(), onFail func(error)) {
+    ticker := time.NewTicker(200 * time.Millisecond)
+    defer ticker.Stop()
+    started := time.Now()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-s.done:
+            return
+        case <-ticker.C:
+        }
+        if len(s.proxies) == 0 {
+            if time.Since(started) >= time.Second {
+                onReady()
+                return
+            }
+            continue
+        }
+        running := 0
+        for _, name := range s.proxies {
+            st, ok := s.svr.StatusExporter().GetProxyStatus(name)
+            if !ok {
+                continue
+            }
+            switch st.Phase {
+            case "running":
+                running++
+            case "start error", "check failed":
+                onFail(fmt.Errorf("代理 %s 启动失败: %s", name, st.Err))
+                return
+            }
+        }
+        if running == len(s.proxies) {
+            onReady()
+            return
+        }
+    }
+}
+
+func (s *libraryService) CheckStatus() error {
+    for _, name := range s.proxies {
+        st, ok := s.svr.StatusExporter().GetProxyStatus(name)
+        if !ok {
+            return fmt.Errorf("代理 %s 不存在", name)
+        }
+        if st.Phase != "running" {
+            if st.Err != "" {
+                return fmt.Errorf("代理 %s 状态 %s: %s", name, st.Phase, st.Err)
+            }
+            return fmt.Errorf("代理 %s 状态 %s", name, st.Phase)
+        }
+    }
+    return nil
+}
+
+func (s *libraryService) Pid() int {
+    return 0
+}
+
+func (s *libraryService) Done() <-chan struct{} {
+    return s.done
+}
+
+func (s *libraryService) Err() error {
+    <-s.done
+    return s.err
+}
+
+func (s *libraryService) Stop(grace time.Duration) {
+    s.once.Do(s.cancel)
+    select {
+    case <-s.done:
+    case <-time.After(grace + time.Second):
+        log.Warn("进程内 frpc 未在宽限期内退出", "grace", grace)
+        <-s.done
+    }
+}
@@ -0,0 +1,24 @@
+//go:build with_frpc_lib
+
+package frpc
+
+import (
+    "testing"
+    "time"
+)
+
+func TestLibraryStopRightAfterStart(t *testing.T) {
+    path := writeTemp(t, "frpc.toml", "serverAddr = \"127.0.0.1\"\nserverPort = 1\nloginFailExit = false\n")
+    for i := 0; i < 5; i++ {
+        s, err := startLibrary(path, "", "", func(string) {}, func() {}, func(error) {})
+        if err != nil {
+            t.Fatal(err)
+        }
+        s.Stop(time.Second)
+        select {
+        case <-s.Done():
+        default:
+            t.Fatal("Stop returned before the service exited")
+        }
+    }
+}
@@ -1,19 +1,21 @@
 package frpc
 
 import (
-    "bufio"
     "context"
     "errors"
     "fmt"
+    "io"
     "net"
     "os"
-    "os/exec"
     "path/filepath"
+    "strconv"
     "strings"
     "sync"
     "time"
 
     "frpcx/internal/config"
+    "frpcx/internal/hooks"
+    "frpcx/internal/logs"
 )
 
 type StatusSnapshot struct {
@@ -23,13 +25,43 @@ type StatusSnapshot struct {
     Health      string
     HealthError string
     LogLines    []string
+    Circuits    []Circuit
+    Visitors    []VisitorStatus
 }
 
+type StatusRecord struct {
+    Time        time.Time `json:"time"`
+    Status      string    `json:"status"`
+    ProfileName string    `json:"profile"`
+    LastError   string    `json:"last_error,omitempty"`
+    Health      string    `json:"health"`
+    HealthError string    `json:"health_error,omitempty"`
+}
+
+type ProfileStats struct {
+    Profile      string
+    Running      bool
+    RunningSince time.Time
+    Starts       int
+    Restarts     int
+    Reconnects   int
+}
+
+const maxHistory = 50
+
+var log = logs.For("frpc")
+
 type Manager struct {
     mu           sync.Mutex
     cfg          *config.AppConfig
-    cmd          *exec.Cmd
+    makeBackend  func(*config.Profile) (Backend, error)
+    backend      Backend
+    grace        time.Duration
     cancel       context.CancelFunc
+    runCtx       context.Context
+    cmds         chan command
+    attempt      *attempt
+    rechecking   bool
     status       string
     profileName  string
     lastError    string
@@ -37,21 +69,41 @@ type Manager struct {
     healthError  string
     activeCfg    string
     activeFrpc   string
-    logLines     []string
+    logs         *logs.Store
+    history      []StatusRecord
+    stats        map[string]*ProfileStats
+    subscribers  []func(Event)
+    events       []Event
+    dispatching  bool
     lastIndex    int
+    latency      []Latency
+    strategy     string
     autoSwitch   bool
     startRunning bool
+    failbackTo   string
+    failbackAt   time.Time
+    penalty      map[string]uint
+    circuits     map[string]*Circuit
+    visitors     []VisitorStatus
 }
 
 func NewManager(cfg *config.AppConfig) *Manager {
-    return &Manager{
-        cfg:        cfg,
-        status:     "stopped",
-        health:     "unknown",
-        lastIndex:  -1,
-        autoSwitch: cfg.AutoSwitch,
-        logLines:   []string{},
-    }
+    m := &Manager{
+        cfg:         cfg,
+        makeBackend: newBackend,
+        status:      "stopped",
+        health:      "unknown",
+        lastIndex:   -1,
+        autoSwitch:  cfg.AutoSwitch,
+        strategy:    cfg.Strategy,
+        stats:       map[string]*ProfileStats{},
+        penalty:     map[string]uint{},
+        circuits:    map[string]*Circuit{},
+        cmds:        make(chan command),
+        logs:        logs.Shared(),
+    }
+    go m.supervise()
+    return m
 }
 
 func (m *Manager) SetConfig(cfg *config.AppConfig) {
@@ -59,6 +111,7 @@ func (m *Manager) SetConfig(cfg *config.AppConfig) {
     defer m.mu.Unlock()
     m.cfg = cfg
     m.autoSwitch = cfg.AutoSwitch
+    m.strategy = cfg.Strategy
 }
 
 func (m *Manager) Status() StatusSnapshot {
@@ -70,228 +123,449 @@ func (m *Manager) Status() StatusSnapshot {
         LastError:   m.lastError,
         Health:      m.health,
         HealthError: m.healthError,
-        LogLines:    append([]string{}, m.logLines...),
+        LogLines:    m.logs.Tail(200),
+        Circuits:    m.circuitsLocked(),
+        Visitors:    append([]VisitorStatus(nil), m.visitors...),
     }
 }
 
-func (m *Manager) Start() {
-    m.StartAuto()
+func (m *Manager) Stats() []ProfileStats {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    out := make([]ProfileStats, 0, len(m.stats))
+    for _, s := range m.stats {
+        out = append(out, *s)
+    }
+    return out
 }
 
-func (m *Manager) StartAuto() {
+func (m *Manager) Active() (config.Profile, string, bool) {
     m.mu.Lock()
-    if m.status == "starting" || m.status == "running" {
-        m.mu.Unlock()
-        return
+    defer m.mu.Unlock()
+    if m.profileName == "" || m.activeCfg == "" {
+        return config.Profile{}, "", false
     }
-    m.status = "starting"
-    m.lastError = ""
-    auto := m.autoSwitch
-    m.mu.Unlock()
-    if auto {
-        go m.startAutoFromIndex(-1)
-    } else {
-        go m.startSingle()
+    for _, p := range m.cfg.Profiles {
+        if p.Name == m.profileName {
+            return p, m.activeCfg, true
+        }
+    }
+    return config.Profile{}, "", false
+}
+
+func (m *Manager) statsLocked(profile string) *ProfileStats {
+    s, ok := m.stats[profile]
+    if !ok {
+        s = &ProfileStats{Profile: profile}
+        m.stats[profile] = s
+    }
+    return s
+}
+
+func (m *Manager) History() []StatusRecord {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return append([]StatusRecord{}, m.history...)
+}
+
+func (m *Manager) recordLocked() {
+    rec := StatusRecord{
+        Time:        time.Now(),
+        Status:      m.status,
+        ProfileName: m.profileName,
+        LastError:   m.lastError,
+        Health:      m.health,
+        HealthError: m.healthError,
+    }
+    if n := len(m.history); n > 0 {
+        last := m.history[n-1]
+        last.Time = rec.Time
+        if last == rec {
+            return
+        }
     }
+    m.history = append(m.history, rec)
+    if len(m.history) > maxHistory {
+        m.history = append([]StatusRecord{}, m.history[len(m.history)-maxHistory:]...)
+    }
+}
+
+func (m *Manager) Logs() []logs.Entry {
+    return m.logs.Entries()
+}
+
+func (m *Manager) LogDir() string {
+    return m.logs.Dir()
+}
+
+func (m *Manager) ExportLogs(w io.Writer) error {
+    return m.logs.Export(w)
+}
+
+func (m *Manager) Start() {
+    m.StartAuto()
+}
+
+func (m *Manager) StartAuto() {
+    _ = m.send(command{kind: cmdStart})
 }
 
-func (m *Manager) startAutoFromIndex(startIndex int) {
+func (m *Manager) startAutoFromIndex(ctx context.Context, startIndex int, from string, kind EventType) {
+    m.mu.Lock()
     profiles := enabledProfiles(m.cfg.Profiles)
+    m.mu.Unlock()
     if len(profiles) == 0 {
         m.setError("没有可用的配置")
         return
     }
 
-    idx := 0
-    if startIndex >= 0 {
-        idx = startIndex
-    } else if m.cfg.ActiveProfile != "" {
-        for i, p := range profiles {
-            if p.Name == m.cfg.ActiveProfile {
-                idx = i
-                break
-            }
-        }
+    order := m.candidateOrder(ctx, profiles, startIndex, from)
+    if from == "" {
+        from = profiles[order[0]].Name
     }
 
-    for i := 0; i < len(profiles); i++ {
-        tryIndex := (idx + i) % len(profiles)
+    tried := 0
+    for _, tryIndex := range order {
+        if ctx.Err() != nil {
+            return
+        }
         p := profiles[tryIndex]
-        if err := m.startProfile(&p, tryIndex); err != nil {
-            m.appendLog(fmt.Sprintf("配置“%s”失败: %v", p.Name, err))
+        if ok, wait := m.allowProfile(p.Name); !ok {
+            log.Info("配置处于熔断冷却中，跳过", "profile", p.Name, "remaining", wait.Round(time.Second))
             continue
         }
+        tried++
+        if err := m.startProfile(ctx, &p, tryIndex); err != nil {
+            if ctx.Err() != nil {
+                return
+            }
+            log.Warn("配置启动失败", "profile", p.Name, "err", err)
+            if kind == EventFailback {
+                m.penalizeFailback(p.Name)
+            }
+            continue
+        }
+        if p.Name != from {
+            log.Warn("已切换配置", "from", from, "profile", p.Name)
+            m.runHooks(&p, hooks.OnFailover, "", from)
+            m.emit(Event{Type: kind, Profile: p.Name, From: from})
+        }
+        m.mu.Lock()
+        runCtx := m.runCtx
+        b := m.backend
+        above := preferredAbove(profiles, m.cfg.ActiveProfile, p.Name)
+        m.mu.Unlock()
+        if runCtx != nil && b != nil && len(above) > 0 {
+            go m.watchFailback(runCtx, b, p.Name)
+        }
         return
     }
 
+    if ctx.Err() != nil {
+        return
+    }
+    if tried == 0 {
+        m.setError("所有配置都处于熔断冷却中")
+        return
+    }
     m.setError("所有配置都失败")
 }
 
-func (m *Manager) startSingle() {
+func (m *Manager) startSingle(ctx context.Context) {
+    m.mu.Lock()
     profiles := enabledProfiles(m.cfg.Profiles)
+    m.mu.Unlock()
     if len(profiles) == 0 {
         m.setError("没有可用的配置")
         return
     }
 
-    idx := 0
-    if m.cfg.ActiveProfile != "" {
-        for i, p := range profiles {
-            if p.Name == m.cfg.ActiveProfile {
-                idx = i
-                break
-            }
-        }
-    }
-
+    idx := m.candidateOrder(ctx, profiles, -1, "")[0]
     p := profiles[idx]
-    if err := m.startProfile(&p, idx); err != nil {
+    if err := m.startProfile(ctx, &p, idx); err != nil && ctx.Err() == nil {
         m.setError(err.Error())
     }
 }
 
-func (m *Manager) StartNext() {
-    m.mu.Lock()
-    nextIndex := m.lastIndex + 1
-    m.mu.Unlock()
-    go m.startAutoFromIndex(nextIndex)
+func (m *Manager) Switch(name string) error {
+    return m.send(command{kind: cmdSwitch, name: name})
 }
 
 func (m *Manager) Stop() {
-    m.mu.Lock()
-    if m.cancel != nil {
-        m.cancel()
-    }
-    cmd := m.cmd
-    m.cmd = nil
-    m.status = "stopped"
-    m.profileName = ""
-    m.health = "stopped"
-    m.healthError = ""
-    m.activeCfg = ""
-    m.activeFrpc = ""
-    m.mu.Unlock()
+    _ = m.send(command{kind: cmdStop})
+}
 
-    if cmd != nil && cmd.Process != nil {
-        _ = cmd.Process.Kill()
+func (m *Manager) startProfile(ctx context.Context, p *config.Profile, index int) error {
+    if err := hooks.Run(ctx, p.Hooks, hooks.Event{Event: hooks.PreStart, Profile: p.Name, ConfigPath: p.ConfigPath}); err != nil {
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+        m.runHooks(p, hooks.OnFailure, err.Error(), "")
+        return err
+    }
+    if err := m.launchProfile(ctx, p, index); err != nil {
+        if ctx.Err() != nil {
+            return err
+        }
+        m.recordFailure(p.Name, err.Error())
+        m.runHooks(p, hooks.OnFailure, err.Error(), "")
+        return err
     }
+    m.recordSuccess(p.Name)
+    m.runHooks(p, hooks.PostReady, "", "")
+    return nil
 }
 
-func (m *Manager) startProfile(p *config.Profile, index int) error {
-    if err := preCheck(p); err != nil {
-        return err
+func (m *Manager) runHooks(p *config.Profile, event, msg, from string) {
+    hooks.RunAsync(p.Hooks, hooks.Event{
+        Event:      event,
+        Profile:    p.Name,
+        From:       from,
+        Message:    msg,
+        ConfigPath: p.ConfigPath,
+    })
+}
+
+func (m *Manager) profileByName(name string) (config.Profile, bool) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, p := range m.cfg.Profiles {
+        if p.Name == name {
+            return p, true
+        }
     }
+    return config.Profile{}, false
+}
 
-    cfgPath, err := resolveConfigPath(p)
-    if err != nil {
+func (m *Manager) launchProfile(actx context.Context, p *config.Profile, index int) error {
+    if err := preCheck(actx, p); err != nil {
         return err
     }
 
-    frpcPath, err := ResolveBinaryPath(p.FrpcPath)
+    b, err := m.makeBackend(p)
     if err != nil {
         return err
     }
+    if err := b.Prepare(actx); err != nil {
+        return err
+    }
 
-    ctx, cancel := context.WithCancel(context.Background())
-    cmd := exec.CommandContext(ctx, frpcPath, append([]string{"-c", cfgPath}, p.ExtraArgs...)...)
-    cmd.Env = os.Environ()
-
-    stdout, _ := cmd.StdoutPipe()
-    stderr, _ := cmd.StderrPipe()
+    vl := newVisitorLog()
+    onLine := func(line string) {
+        m.logOutput(p.Name, line)
+        vl.observe(line)
+        if isReconnectLog(line) {
+            m.mu.Lock()
+            m.statsLocked(p.Name).Reconnects++
+            m.mu.Unlock()
+        }
+    }
 
-    if err := cmd.Start(); err != nil {
+    ctx, cancel := context.WithCancel(context.Background())
+    if err := b.Start(onLine); err != nil {
         cancel()
         return err
     }
+    grace := time.Duration(defaultInt(p.StopGraceSec, 5)) * time.Second
+    abort := func() {
+        cancel()
+        m.mu.Lock()
+        if m.backend == b {
+            m.backend = nil
+        }
+        m.mu.Unlock()
+        b.Stop(grace)
+    }
+
+    var pid int
+    var binary, cfgPath string
+    if info, ok := b.(processInfo); ok {
+        pid, binary, cfgPath = info.Pid(), info.Binary(), info.ConfigPath()
+    }
 
     m.mu.Lock()
-    m.cmd = cmd
+    if actx.Err() != nil {
+        m.mu.Unlock()
+        cancel()
+        b.Stop(grace)
+        return actx.Err()
+    }
+    m.backend = b
+    m.grace = grace
     m.cancel = cancel
+    m.runCtx = ctx
     m.status = "starting"
     m.profileName = p.Name
     m.lastIndex = index
     m.activeCfg = cfgPath
-    m.activeFrpc = frpcPath
+    m.activeFrpc = binary
+    st := m.statsLocked(p.Name)
+    st.Starts++
+    if st.Starts > 1 {
+        st.Restarts++
+    }
+    m.recordLocked()
     m.mu.Unlock()
-
-    readyCh := make(chan struct{})
-    failCh := make(chan error, 1)
+    if pid > 0 {
+        log.Info("隧道已启动", "profile", p.Name, "backend", backendName(p), "pid", pid, "config", cfgPath)
+        m.trackProcess(b, RunRecord{
+            PID:        pid,
+            Owner:      os.Getpid(),
+            Profile:    p.Name,
+            Backend:    backendName(p),
+            ConfigPath: cfgPath,
+            FrpcPath:   binary,
+            StartedAt:  time.Now(),
+        })
+    } else {
+        log.Info("隧道已在进程内启动", "profile", p.Name, "backend", backendName(p), "config", cfgPath)
+    }
 
     startTimeout := time.Duration(defaultInt(p.StartTimeoutSec, 8)) * time.Second
+    readyCtx, readyCancel := context.WithTimeout(actx, startTimeout)
+    err = b.Ready(readyCtx)
+    timedOut := errors.Is(readyCtx.Err(), context.DeadlineExceeded)
+    readyCancel()
+    if err != nil {
+        if actx.Err() != nil {
+            abort()
+            return actx.Err()
+        }
+        if timedOut {
+            log.Error("启动超时", "profile", p.Name, "timeout", startTimeout)
+        } else {
+            log.Error("启动失败", "profile", p.Name, "err", err)
+        }
+        abort()
+        return err
+    }
 
-    scan := func(r *bufio.Scanner) {
-        for r.Scan() {
-            line := r.Text()
-            m.appendLog(line)
-            if ok, err := classifyLog(line); ok {
-                select {
-                case <-readyCh:
-                default:
-                    close(readyCh)
-                }
-            } else if err != nil {
-                select {
-                case failCh <- err:
-                default:
-                }
-            }
+    m.setRunning(p.Name)
+    if p.RequireStatus {
+        m.setHealth("checking", "")
+    } else {
+        m.setHealth("disabled", "")
+    }
+    if err := m.waitForStatusOK(actx, b, p); err != nil {
+        if actx.Err() != nil {
+            abort()
+            return actx.Err()
+        }
+        log.Error("状态检查失败", "profile", p.Name, "err", err)
+        m.setHealth("fail", err.Error())
+        abort()
+        return err
+    }
+
+    visitors := loadVisitors(cfgPath)
+    if err := m.waitForVisitors(actx, p, visitors, vl); err != nil {
+        if actx.Err() != nil {
+            abort()
+            return actx.Err()
         }
+        log.Error("访问者未就绪", "profile", p.Name, "err", err)
+        abort()
+        return err
+    }
+
+    if actx.Err() != nil {
+        abort()
+        return actx.Err()
+    }
+    go m.watchExit(ctx, b)
+
+    if p.RequireStatus {
+        go m.monitorStatus(ctx, b, p)
+    }
+    if len(visitors) > 0 {
+        go m.monitorVisitors(ctx, p, visitors, vl)
     }
 
-    go scan(bufio.NewScanner(stdout))
-    go scan(bufio.NewScanner(stderr))
+    m.emit(Event{Type: EventReady, Profile: p.Name})
+    return nil
+}
 
-    exitCh := make(chan error, 1)
+func (m *Manager) watchExit(ctx context.Context, b Backend) {
+    <-b.Done()
+    err := b.Err()
+    if ctx.Err() != nil {
+        return
+    }
+    msg := "进程退出"
+    if err != nil {
+        msg = fmt.Sprintf("进程退出: %v", err)
+    }
+    m.post(command{kind: cmdFailed, backend: b, msg: msg})
+}
+
+func (m *Manager) trackProcess(b Backend, rec RunRecord) {
+    recordProcess(rec)
     go func() {
-        exitCh <- cmd.Wait()
+        <-b.Done()
+        forgetProcess(rec.PID)
     }()
+}
 
-    select {
-    case err := <-failCh:
-        m.appendLog(fmt.Sprintf("启动失败: %v", err))
-        _ = cmd.Process.Kill()
-        return err
-    case <-readyCh:
-        m.setRunning(p.Name)
-        if p.RequireStatus {
-            m.setHealth("checking", "")
-        } else {
-            m.setHealth("disabled", "")
-        }
-        if err := m.waitForStatusOK(frpcPath, cfgPath, p); err != nil {
-            m.appendLog(fmt.Sprintf("状态检查失败: %v", err))
-            m.setHealth("fail", err.Error())
-            _ = cmd.Process.Kill()
-            return err
-        }
-    case <-time.After(startTimeout):
-        err := errors.New("启动超时")
-        m.appendLog("启动超时")
-        _ = cmd.Process.Kill()
+func (m *Manager) Adopt(rec RunRecord) error {
+    return m.send(command{kind: cmdAdopt, rec: rec})
+}
+
+func (m *Manager) adopt(rec RunRecord) error {
+    if !processAlive(rec.PID) {
+        forgetProcess(rec.PID)
+        return errors.New("进程已退出")
+    }
+    p, ok := m.profileByName(rec.Profile)
+    if !ok {
+        return fmt.Errorf("未找到配置“%s”", rec.Profile)
+    }
+
+    if rec.Backend != "" && rec.Backend != backendName(&p) {
+        return fmt.Errorf("残留进程的隧道类型 %s 与配置不一致", rec.Backend)
+    }
+    b, err := m.makeBackend(&p)
+    if err != nil {
         return err
-    case err := <-exitCh:
-        if err != nil {
-            return fmt.Errorf("进程提前退出: %w", err)
-        }
-        return errors.New("进程退出")
+    }
+    ad, ok := b.(adoptable)
+    if !ok {
+        return fmt.Errorf("%s 不支持接管", backendName(&p))
     }
 
-    go func() {
-        err := <-exitCh
-        if err == nil {
-            m.setError("进程退出")
-        } else {
-            m.setError(fmt.Sprintf("进程退出: %v", err))
-        }
-        if m.autoSwitch {
-            m.StartNext()
+    m.mu.Lock()
+    if m.status == "starting" || m.status == "running" {
+        m.mu.Unlock()
+        return errors.New("已有正在运行的配置")
+    }
+    ad.adopt(rec)
+    ctx, cancel := context.WithCancel(context.Background())
+    m.backend = b
+    m.grace = time.Duration(defaultInt(p.StopGraceSec, 5)) * time.Second
+    m.cancel = cancel
+    m.runCtx = ctx
+    m.profileName = p.Name
+    m.activeCfg = rec.ConfigPath
+    m.activeFrpc = rec.FrpcPath
+    m.lastIndex = -1
+    for i, ep := range enabledProfiles(m.cfg.Profiles) {
+        if ep.Name == p.Name {
+            m.lastIndex = i
         }
-    }()
+    }
+    m.mu.Unlock()
+
+    rec.Owner = os.Getpid()
+    m.trackProcess(b, rec)
+    log.Info("已接管残留进程", "profile", p.Name, "backend", backendName(&p), "pid", rec.PID)
 
+    m.setRunning(p.Name)
     if p.RequireStatus {
-        go m.monitorStatus(ctx, frpcPath, cfgPath, p)
+        m.setHealth("checking", "")
+        go m.monitorStatus(ctx, b, &p)
+    } else {
+        m.setHealth("disabled", "")
     }
-
+    go m.watchExit(ctx, b)
     return nil
 }
 
@@ -305,22 +579,26 @@ func enabledProfiles(profiles []config.Profile) []config.Profile {
     return out
 }
 
-func preCheck(p *config.Profile) error {
+func preCheck(ctx context.Context, p *config.Profile) error {
     if p.ServerAddr != "" && p.ServerPort > 0 {
-        addr := fmt.Sprintf("%s:%d", p.ServerAddr, p.ServerPort)
-        d := time.Duration(defaultInt(p.HealthTimeoutSec, 5)) * time.Second
-        conn, err := net.DialTimeout("tcp", addr, d)
-        if err != nil {
+        addr := net.JoinHostPort(p.ServerAddr, strconv.Itoa(p.ServerPort))
+        timeout := time.Duration(defaultInt(p.HealthTimeoutSec, 5)) * time.Second
+        conn, err := dialServer(ctx, p, addr, timeout)
+        switch {
+        case errors.Is(err, errNoProxyCheck):
+            log.Debug("代理类型不支持连通检查，已跳过", "profile", p.Name)
+        case err != nil:
             return fmt.Errorf("服务器连通失败: %w", err)
+        default:
+            _ = conn.Close()
         }
-        _ = conn.Close()
     }
 
     if len(p.LocalCheckPorts) > 0 {
-        d := time.Duration(defaultInt(p.HealthTimeoutSec, 2)) * time.Second
+        d := net.Dialer{Timeout: time.Duration(defaultInt(p.HealthTimeoutSec, 2)) * time.Second}
         for _, port := range p.LocalCheckPorts {
             addr := fmt.Sprintf("127.0.0.1:%d", port)
-            conn, err := net.DialTimeout("tcp", addr, d)
+            conn, err := d.DialContext(ctx, "tcp", addr)
             if err != nil {
                 return fmt.Errorf("本地端口 %d 不可达: %w", port, err)
             }
@@ -347,7 +625,7 @@ func classifyLog(line string) (bool, error) {
         "login to server failed",
         "authentication failed",
         "invalid token",
-        "failed to" ,
+        "failed to",
     }
     for _, p := range failurePatterns {
         if strings.Contains(l, p) {
@@ -362,13 +640,13 @@ func classifyLog(line string) (bool, error) {
     return false, nil
 }
 
-func (m *Manager) appendLog(line string) {
-    m.mu.Lock()
-    defer m.mu.Unlock()
-    m.logLines = append(m.logLines, line)
-    if len(m.logLines) > 200 {
-        m.logLines = m.logLines[len(m.logLines)-200:]
-    }
+func isReconnectLog(line string) bool {
+    l := strings.ToLower(line)
+    return strings.Contains(l, "try to reconnect") || strings.Contains(l, "reconnect to server")
+}
+
+func (m *Manager) logOutput(profile, line string) {
+    m.logs.Append(logs.FrpcEntry(profile, line))
 }
 
 func (m *Manager) setRunning(profile string) {
@@ -377,33 +655,45 @@ func (m *Manager) setRunning(profile string) {
     m.status = "running"
     m.profileName = profile
     m.lastError = ""
+    st := m.statsLocked(profile)
+    st.Running = true
+    st.RunningSince = time.Now()
+    m.recordLocked()
+    log.Info("运行中", "profile", profile)
 }
 
 func (m *Manager) setError(msg string) {
     m.mu.Lock()
-    defer m.mu.Unlock()
     m.status = "error"
     m.lastError = msg
+    profile := m.profileName
+    if profile != "" {
+        m.statsLocked(profile).Running = false
+    }
+    m.recordLocked()
+    m.mu.Unlock()
+    log.Error(msg, "profile", profile)
+    m.emit(Event{Type: EventFailed, Profile: profile, Message: msg})
 }
 
 func (m *Manager) setHealth(status, err string) {
     m.mu.Lock()
-    defer m.mu.Unlock()
+    prev := m.health
+    running := m.status == "running"
+    profile := m.profileName
     m.health = status
     m.healthError = err
-}
-
-func (m *Manager) failAndSwitch(msg string) {
-    m.setError(msg)
-    m.setHealth("fail", msg)
-    m.mu.Lock()
-    cmd := m.cmd
+    m.recordLocked()
     m.mu.Unlock()
-    if cmd != nil && cmd.Process != nil {
-        _ = cmd.Process.Kill()
+
+    if !running || prev == status {
+        return
     }
-    if m.autoSwitch {
-        m.StartNext()
+    switch {
+    case status == "fail" && prev == "ok":
+        m.emit(Event{Type: EventDegraded, Profile: profile, Message: err})
+    case status == "ok" && prev == "fail":
+        m.emit(Event{Type: EventRecovered, Profile: profile})
     }
 }
 
@@ -415,6 +705,9 @@ func defaultInt(v, d int) int {
 }
 
 func resolveConfigPath(p *config.Profile) (string, error) {
+    if p.Template != "" {
+        return RenderProfile(p)
+    }
     cfgPath := p.ConfigPath
     if cfgPath == "" && p.RemoteConfigPath != "" {
         if local, err := cachedConfigPath(p.Name); err == nil {
@@ -430,7 +723,7 @@ func resolveConfigPath(p *config.Profile) (string, error) {
     return cfgPath, nil
 }
 
-func (m *Manager) waitForStatusOK(frpcPath, cfgPath string, p *config.Profile) error {
+func (m *Manager) waitForStatusOK(ctx context.Context, b Backend, p *config.Profile) error {
     if !p.RequireStatus {
         return nil
     }
@@ -438,15 +731,19 @@ func (m *Manager) waitForStatusOK(frpcPath, cfgPath string, p *config.Profile) e
     deadline := time.Now().Add(timeout)
     var lastErr error
     for time.Now().Before(deadline) {
-        if err := m.checkStatusOnce(frpcPath, cfgPath, p); err == nil {
-            m.appendLog("状态检查通过")
+        if err := checkStatusOnce(b, p); err == nil {
+            log.Info("状态检查通过", "profile", p.Name)
             m.setHealth("ok", "")
             return nil
         } else {
             lastErr = err
             m.setHealth("fail", err.Error())
         }
-        time.Sleep(500 * time.Millisecond)
+        select {
+        case <-ctx.Done():
+            return ctx.Err()
+        case <-time.After(500 * time.Millisecond):
+        }
     }
     if lastErr != nil {
         return lastErr
@@ -454,7 +751,7 @@ func (m *Manager) waitForStatusOK(frpcPath, cfgPath string, p *config.Profile) e
     return errors.New("状态检查超时")
 }
 
-func (m *Manager) monitorStatus(ctx context.Context, frpcPath, cfgPath string, p *config.Profile) {
+func (m *Manager) monitorStatus(ctx context.Context, b Backend, p *config.Profile) {
     interval := time.Duration(defaultInt(p.StatusIntervalSec, 5)) * time.Second
     ticker := time.NewTicker(interval)
     defer ticker.Stop()
@@ -464,12 +761,12 @@ func (m *Manager) monitorStatus(ctx context.Context, frpcPath, cfgPath string, p
         case <-ctx.Done():
             return
         case <-ticker.C:
-            if err := m.checkStatusOnce(frpcPath, cfgPath, p); err != nil {
+            if err := checkStatusOnce(b, p); err != nil {
                 failures++
                 m.setHealth("fail", err.Error())
                 if failures >= 3 {
-                    m.appendLog(fmt.Sprintf("状态监测失败: %v", err))
-                    m.failAndSwitch("状态监测失败")
+                    log.Error("状态监测失败", "profile", p.Name, "failures", failures, "err", err)
+                    m.post(command{kind: cmdFailed, backend: b, msg: "状态监测失败", degraded: true})
                     return
                 }
             } else {
@@ -480,51 +777,26 @@ func (m *Manager) monitorStatus(ctx context.Context, frpcPath, cfgPath string, p
     }
 }
 
-func (m *Manager) checkStatusOnce(frpcPath, cfgPath string, p *config.Profile) error {
+func checkStatusOnce(b Backend, p *config.Profile) error {
     ctx, cancel := context.WithTimeout(context.Background(), time.Duration(defaultInt(p.HealthTimeoutSec, 3))*time.Second)
     defer cancel()
-    cmd := exec.CommandContext(ctx, frpcPath, "status", "-c", cfgPath)
-    out, err := cmd.CombinedOutput()
-    if err != nil {
-        msg := strings.TrimSpace(string(out))
-        if msg == "" {
-            msg = err.Error()
-        }
-        return errors.New(msg)
-    }
-    return nil
+    return b.Health(ctx)
 }
 
 func (m *Manager) CheckStatusNow() error {
     m.mu.Lock()
     name := m.profileName
-    cfg := m.cfg
+    b := m.backend
     m.mu.Unlock()
 
-    if name == "" {
+    if name == "" || b == nil {
         return errors.New("当前没有正在运行的配置")
     }
-    var p *config.Profile
-    for i := range cfg.Profiles {
-        if cfg.Profiles[i].Name == name {
-            p = &cfg.Profiles[i]
-            break
-        }
-    }
-    if p == nil {
+    p, ok := m.profileByName(name)
+    if !ok {
         return errors.New("未找到当前配置")
     }
-    cfgPath, err := resolveConfigPath(p)
-    if err != nil {
-        m.setHealth("fail", err.Error())
-        return err
-    }
-    frpcPath, err := ResolveBinaryPath(p.FrpcPath)
-    if err != nil {
-        m.setHealth("fail", err.Error())
-        return err
-    }
-    if err := m.checkStatusOnce(frpcPath, cfgPath, p); err != nil {
+    if err := checkStatusOnce(b, &p); err != nil {
         m.setHealth("fail", err.Error())
         return err
     }
@@ -0,0 +1,161 @@
+package frpc
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "regexp"
+    "strings"
+    "time"
+
+    "frpcx/internal/config"
+)
+
+const (
+    AuthToken = "token"
+    AuthOIDC  = "oidc"
+
+    OIDCSecretEnv = config.OIDCSecretEnv
+)
+
+var envRefRe = regexp.MustCompile(`^\{\{\s*\.Envs\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+
+type OIDCOptions struct {
+    ClientID         string `toml:"clientID"`
+    ClientSecret     string `toml:"clientSecret"`
+    Audience         string `toml:"audience"`
+    Scope            string `toml:"scope"`
+    TokenEndpointURL string `toml:"tokenEndpointURL"`
+}
+
+func OIDCSecretKey(profile string) string {
+    return "oidc/" + profile
+}
+
+func OIDCSecretRef() string {
+    return "{{ .Envs." + OIDCSecretEnv + " }}"
+}
+
+func (o OIDCOptions) Validate() error {
+    if o.ClientID == "" {
+        return errors.New("请填写 OIDC Client ID")
+    }
+    if o.TokenEndpointURL == "" {
+        return errors.New("请填写 OIDC Token 地址")
+    }
+    u, err := url.Parse(o.TokenEndpointURL)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+        return fmt.Errorf("OIDC Token 地址无效: %s", o.TokenEndpointURL)
+    }
+    return nil
+}
+
+func resolveOIDCSecret(p *config.Profile, o OIDCOptions) (secret, env string, err error) {
+    m := envRefRe.FindStringSubmatch(o.ClientSecret)
+    if m == nil {
+        return o.ClientSecret, "", nil
+    }
+    env = m[1]
+    secret, err = config.GetSecret(OIDCSecretKey(p.Name))
+    if err != nil {
+        return "", "", fmt.Errorf("读取 OIDC 密钥失败: %w", err)
+    }
+    if secret == "" {
+        secret = os.Getenv(env)
+    }
+    if secret == "" {
+        return "", "", fmt.Errorf("未找到 OIDC Client Secret（密钥库或环境变量 %s）", env)
+    }
+    return secret, env, nil
+}
+
+func FetchOIDCToken(ctx context.Context, o OIDCOptions, secret string) (string, error) {
+    if err := o.Validate(); err != nil {
+        return "", err
+    }
+    form := url.Values{"grant_type": {"client_credentials"}}
+    if o.Audience != "" {
+        form.Set("audience", o.Audience)
+    }
+    if o.Scope != "" {
+        form.Set("scope", o.Scope)
+    }
+    token, status, err := requestOIDCToken(ctx, o, secret, form, true)
+    if err != nil && (status == http.StatusBadRequest || status == http.StatusUnauthorized) {
+        token, _, err = requestOIDCToken(ctx, o, secret, form, false)
+    }
+    return token, err
+}
+
+func requestOIDCToken(ctx context.Context, o OIDCOptions, secret string, form url.Values, basic bool) (string, int, error) {
+    body := url.Values{}
+    for k, v := range form {
+        body[k] = v
+    }
+    if !basic {
+        body.Set("client_id", o.ClientID)
+        body.Set("client_secret", secret)
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.TokenEndpointURL, strings.NewReader(body.Encode()))
+    if err != nil {
+        return "", 0, err
+    }
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    req.Header.Set("Accept", "application/json")
+    if basic {
+        req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(secret))
+    }
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return "", 0, fmt.Errorf("请求 OIDC Token 失败: %w", err)
+    }
+    defer resp.Body.Close()
+    b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+    var out struct {
+        AccessToken string `json:"access_token"`
+        Error       string `json:"error"`
+        Description string `json:"error_description"`
+    }
+    _ = json.Unmarshal(b, &out)
+    if resp.StatusCode != http.StatusOK {
+        msg := out.Error
+        if out.Description != "" {
+            msg += ": " + out.Description
+        }
+        if msg == "" {
+            msg = resp.Status
+        }
+        return "", resp.StatusCode, fmt.Errorf("OIDC 认证失败: %s", msg)
+    }
+    if out.AccessToken == "" {
+        return "", resp.StatusCode, errors.New("OIDC 响应中没有 access_token")
+    }
+    return out.AccessToken, resp.StatusCode, nil
+}
+
+func (b *frpcBackend) checkOIDC(ctx context.Context, info *ClientInfo) error {
+    if info.AuthMethod != AuthOIDC {
+        return nil
+    }
+    secret, env, err := resolveOIDCSecret(b.profile, info.OIDC)
+    if err != nil {
+        return err
+    }
+    ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+    defer cancel()
+    if _, err := FetchOIDCToken(ctx, info.OIDC, secret); err != nil {
+        return err
+    }
+    b.secret = secret
+    if env != "" {
+        b.env = []string{env + "=" + secret}
+    }
+    log.Info("OIDC 认证检查通过", "profile", b.profile.Name, "endpoint", info.OIDC.TokenEndpointURL)
+    return nil
+}
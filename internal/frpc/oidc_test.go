@@ -0,0 +1,146 @@
+package frpc
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync/atomic"
+    "testing"
+
+    "frpcx/internal/config"
+)
+
+func tokenServer(t *testing.T, basic bool) (*httptest.Server, *atomic.Int32) {
+    t.Helper()
+    var calls atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        calls.Add(1)
+        if err := r.ParseForm(); err != nil {
+            t.Error(err)
+        }
+        if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("audience") != "frps" {
+            w.WriteHeader(http.StatusBadRequest)
+            _ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_request"})
+            return
+        }
+        id, secret, ok := r.BasicAuth()
+        if !basic {
+            if ok {
+                w.WriteHeader(http.StatusUnauthorized)
+                _ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client", "error_description": "basic auth not allowed"})
+                return
+            }
+            id, secret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+        }
+        if id != "frpc" || secret != "s3cret" {
+            w.WriteHeader(http.StatusUnauthorized)
+            _ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client", "error_description": "bad secret"})
+            return
+        }
+        _ = json.NewEncoder(w).Encode(map[string]string{"access_token": "tok", "token_type": "Bearer"})
+    }))
+    t.Cleanup(srv.Close)
+    return srv, &calls
+}
+
+func TestFetchOIDCTokenBasic(t *testing.T) {
+    srv, calls := tokenServer(t, true)
+    o := OIDCOptions{ClientID: "frpc", Audience: "frps", TokenEndpointURL: srv.URL}
+    tok, err := FetchOIDCToken(context.Background(), o, "s3cret")
+    if err != nil || tok != "tok" {
+        t.Fatalf("token = %q, %v", tok, err)
+    }
+    if calls.Load() != 1 {
+        t.Fatalf("calls = %d, want 1", calls.Load())
+    }
+}
+
+func TestFetchOIDCTokenFormFallback(t *testing.T) {
+    srv, calls := tokenServer(t, false)
+    o := OIDCOptions{ClientID: "frpc", Audience: "frps", TokenEndpointURL: srv.URL}
+    tok, err := FetchOIDCToken(context.Background(), o, "s3cret")
+    if err != nil || tok != "tok" {
+        t.Fatalf("token = %q, %v", tok, err)
+    }
+    if calls.Load() != 2 {
+        t.Fatalf("calls = %d, want 2", calls.Load())
+    }
+}
+
+func TestFetchOIDCTokenErrors(t *testing.T) {
+    srv, _ := tokenServer(t, true)
+    o := OIDCOptions{ClientID: "frpc", Audience: "frps", TokenEndpointURL: srv.URL}
+    _, err := FetchOIDCToken(context.Background(), o, "wrong")
+    if err == nil || !strings.Contains(err.Error(), "invalid_client: bad secret") {
+        t.Fatalf("err = %v", err)
+    }
+
+    empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        _, _ = w.Write([]byte(`{"token_type":"Bearer"}`))
+    }))
+    defer empty.Close()
+    o.TokenEndpointURL = empty.URL
+    if _, err := FetchOIDCToken(context.Background(), o, "s3cret"); err == nil || !strings.Contains(err.Error(), "access_token") {
+        t.Fatalf("err = %v", err)
+    }
+
+    for _, bad := range []OIDCOptions{
+        {TokenEndpointURL: srv.URL},
+        {ClientID: "frpc"},
+        {ClientID: "frpc", TokenEndpointURL: "ftp://idp/token"},
+    } {
+        if _, err := FetchOIDCToken(context.Background(), bad, "s3cret"); err == nil {
+            t.Errorf("%+v accepted", bad)
+        }
+    }
+}
+
+func TestResolveOIDCSecret(t *testing.T) {
+    useTempConfigDir(t)
+    t.Setenv(OIDCSecretEnv, "")
+    p := &config.Profile{Name: "home"}
+    ref := OIDCOptions{ClientSecret: OIDCSecretRef()}
+
+    if secret, env, err := resolveOIDCSecret(p, OIDCOptions{ClientSecret: "inline"}); err != nil || secret != "inline" || env != "" {
+        t.Fatalf("inline = %q, %q, %v", secret, env, err)
+    }
+    if _, _, err := resolveOIDCSecret(p, ref); err == nil {
+        t.Fatal("missing secret accepted")
+    }
+
+    t.Setenv(OIDCSecretEnv, "from-env")
+    if secret, env, err := resolveOIDCSecret(p, ref); err != nil || secret != "from-env" || env != OIDCSecretEnv {
+        t.Fatalf("env = %q, %q, %v", secret, env, err)
+    }
+
+    if err := config.SetSecret(OIDCSecretKey("home"), "from-store"); err != nil {
+        t.Fatal(err)
+    }
+    if secret, _, err := resolveOIDCSecret(p, ref); err != nil || secret != "from-store" {
+        t.Fatalf("store = %q, %v", secret, err)
+    }
+}
+
+func TestCheckOIDC(t *testing.T) {
+    useTempConfigDir(t)
+    t.Setenv(OIDCSecretEnv, "")
+    srv, _ := tokenServer(t, true)
+    if err := config.SetSecret(OIDCSecretKey("home"), "s3cret"); err != nil {
+        t.Fatal(err)
+    }
+    b := &frpcBackend{profile: &config.Profile{Name: "home"}}
+    info := &ClientInfo{AuthMethod: AuthOIDC, OIDC: OIDCOptions{
+        ClientID:         "frpc",
+        ClientSecret:     OIDCSecretRef(),
+        Audience:         "frps",
+        TokenEndpointURL: srv.URL,
+    }}
+    if err := b.checkOIDC(context.Background(), info); err != nil {
+        t.Fatal(err)
+    }
+    if b.secret != "s3cret" || len(b.env) != 1 || b.env[0] != OIDCSecretEnv+"=s3cret" {
+        t.Fatalf("secret = %q, env = %v", b.secret, b.env)
+    }
+}
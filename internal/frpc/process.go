@@ -0,0 +1,139 @@
+package frpc
+
+import (
+    "bytes"
+    "errors"
+    "os/exec"
+    "sync"
+    "time"
+)
+
+type runner interface {
+    Pid() int
+    Done() <-chan struct{}
+    Err() error
+    Stop(grace time.Duration)
+}
+
+type process struct {
+    pid  int
+    cmd  *exec.Cmd
+    done chan struct{}
+    err  error
+    once sync.Once
+}
+
+func startProcess(cmd *exec.Cmd, onLine func(string)) (*process, error) {
+    out := &lineWriter{fn: onLine}
+    cmd.Stdout = out
+    cmd.Stderr = out
+    cmd.WaitDelay = 2 * time.Second
+    setProcessGroup(cmd)
+
+    if err := cmd.Start(); err != nil {
+        return nil, err
+    }
+    p := &process{pid: cmd.Process.Pid, cmd: cmd, done: make(chan struct{})}
+    go func() {
+        p.err = cmd.Wait()
+        out.flush()
+        close(p.done)
+    }()
+    return p, nil
+}
+
+func adoptProcess(pid int) *process {
+    p := &process{pid: pid, done: make(chan struct{})}
+    go func() {
+        ticker := time.NewTicker(time.Second)
+        defer ticker.Stop()
+        for range ticker.C {
+            if !processAlive(pid) {
+                p.err = errors.New("接管的进程已退出")
+                close(p.done)
+                return
+            }
+        }
+    }()
+    return p
+}
+
+func (p *process) Pid() int {
+    return p.pid
+}
+
+func (p *process) Done() <-chan struct{} {
+    return p.done
+}
+
+func (p *process) Err() error {
+    <-p.done
+    return p.err
+}
+
+func (p *process) Stop(grace time.Duration) {
+    p.once.Do(func() {
+        if p.exited() {
+            return
+        }
+        if err := terminateGroup(p.pid); err == nil {
+            select {
+            case <-p.done:
+                return
+            case <-time.After(grace):
+                log.Warn("frpc 未在宽限期内退出，强制结束", "pid", p.pid, "grace", grace)
+            }
+        }
+        p.kill()
+        <-p.done
+    })
+    <-p.done
+}
+
+func (p *process) exited() bool {
+    select {
+    case <-p.done:
+        return true
+    default:
+    }
+    return p.cmd == nil && !processAlive(p.pid)
+}
+
+func (p *process) kill() {
+    if !p.exited() {
+        killGroup(p.pid)
+    }
+    if p.cmd != nil {
+        _ = p.cmd.Process.Kill()
+    }
+}
+
+type lineWriter struct {
+    mu  sync.Mutex
+    buf bytes.Buffer
+    fn  func(string)
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.buf.Write(b)
+    for {
+        i := bytes.IndexByte(w.buf.Bytes(), '\n')
+        if i < 0 {
+            break
+        }
+        line := string(bytes.TrimRight(w.buf.Next(i+1), "\r\n"))
+        w.fn(line)
+    }
+    return len(b), nil
+}
+
+func (w *lineWriter) flush() {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.buf.Len() > 0 {
+        w.fn(string(bytes.TrimRight(w.buf.Bytes(), "\r\n")))
+        w.buf.Reset()
+    }
+}
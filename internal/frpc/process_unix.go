@@ -0,0 +1,48 @@
+//go:build !windows
+
+package frpc
+
+import (
+    "errors"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
+    "strconv"
+    "strings"
+    "syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+    cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func terminateGroup(pid int) error {
+    return syscall.Kill(-pid, syscall.SIGTERM)
+}
+
+func killGroup(pid int) {
+    _ = syscall.Kill(-pid, syscall.SIGKILL)
+    _ = syscall.Kill(pid, syscall.SIGKILL)
+}
+
+func processAlive(pid int) bool {
+    if pid <= 0 {
+        return false
+    }
+    err := syscall.Kill(pid, 0)
+    return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func processExe(pid int) string {
+    if runtime.GOOS == "linux" {
+        if exe, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "exe")); err == nil {
+            return strings.TrimSuffix(exe, " (deleted)")
+        }
+    }
+    out, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "comm=").Output()
+    if err != nil {
+        return ""
+    }
+    return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,54 @@
+//go:build !windows
+
+package frpc
+
+import (
+    "os/exec"
+    "testing"
+    "time"
+)
+
+func TestProcessStop(t *testing.T) {
+    p, err := startProcess(exec.Command("sleep", "30"), func(string) {})
+    if err != nil {
+        t.Fatal(err)
+    }
+    start := time.Now()
+    p.Stop(5 * time.Second)
+    if time.Since(start) > 3*time.Second {
+        t.Fatal("SIGTERM was not delivered to the process group")
+    }
+    if processAlive(p.Pid()) {
+        t.Fatal("process still alive after Stop")
+    }
+}
+
+func TestProcessStopAfterExit(t *testing.T) {
+    p, err := startProcess(exec.Command("true"), func(string) {})
+    if err != nil {
+        t.Fatal(err)
+    }
+    <-p.Done()
+    if !p.exited() {
+        t.Fatal("reaped process not reported as exited")
+    }
+    p.Stop(5 * time.Second)
+}
+
+func TestAdoptedProcessStop(t *testing.T) {
+    cmd := exec.Command("sleep", "30")
+    setProcessGroup(cmd)
+    if err := cmd.Start(); err != nil {
+        t.Fatal(err)
+    }
+    go func() { _ = cmd.Wait() }()
+
+    p := adoptProcess(cmd.Process.Pid)
+    if p.exited() {
+        t.Fatal("live process reported as exited")
+    }
+    p.Stop(5 * time.Second)
+    if processAlive(cmd.Process.Pid) {
+        t.Fatal("adopted process still alive after Stop")
+    }
+}
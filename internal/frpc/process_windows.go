@@ -0,0 +1,70 @@
+//go:build windows
+
+package frpc
+
+import (
+    "errors"
+    "os/exec"
+    "strconv"
+    "strings"
+    "syscall"
+)
+
+var procGenerateConsoleCtrlEvent = syscall.NewLazyDLL("kernel32.dll").NewProc("GenerateConsoleCtrlEvent")
+
+const (
+    ctrlBreakEvent                 = 1
+    processQueryLimitedInformation = 0x1000
+    stillActive                    = 259
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+    cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+func terminateGroup(pid int) error {
+    r, _, err := procGenerateConsoleCtrlEvent.Call(ctrlBreakEvent, uintptr(pid))
+    if r == 0 {
+        if err == nil {
+            err = errors.New("GenerateConsoleCtrlEvent failed")
+        }
+        return err
+    }
+    return nil
+}
+
+func killGroup(pid int) {
+    kill := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid))
+    kill.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+    _ = kill.Run()
+}
+
+func processAlive(pid int) bool {
+    if pid <= 0 {
+        return false
+    }
+    h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+    if err != nil {
+        return false
+    }
+    defer syscall.CloseHandle(h)
+    var code uint32
+    if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+        return false
+    }
+    return code == stillActive
+}
+
+func processExe(pid int) string {
+    cmd := exec.Command("tasklist", "/FI", "PID eq "+strconv.Itoa(pid), "/FO", "CSV", "/NH")
+    cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+    out, err := cmd.Output()
+    if err != nil {
+        return ""
+    }
+    fields := strings.Split(strings.TrimSpace(string(out)), ",")
+    if len(fields) == 0 {
+        return ""
+    }
+    return strings.Trim(fields[0], "\"")
+}
@@ -0,0 +1,310 @@
+package frpc
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "net"
+    "net/http"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/BurntSushi/toml"
+    "golang.org/x/net/proxy"
+
+    "frpcx/internal/config"
+    "frpcx/internal/logs"
+)
+
+var errNoProxyCheck = errors.New("该代理类型不支持连通检查")
+
+func ResolveProxy(p *config.Profile) (string, error) {
+    if backendName(p) != BackendFrpc {
+        return "", nil
+    }
+    if p.ProxyURL != "" {
+        return p.ProxyURL, ValidateProxyURL(p.ProxyURL)
+    }
+    var info *ClientInfo
+    if cfgPath, err := resolveConfigPath(p); err == nil {
+        info, _ = LoadClientInfo(cfgPath)
+    }
+    if info != nil && info.ProxyURL != "" {
+        return info.ProxyURL, ValidateProxyURL(info.ProxyURL)
+    }
+    if !p.UseSystemProxy {
+        return "", nil
+    }
+    host := p.ServerAddr
+    if host == "" && info != nil {
+        host = info.ServerAddr
+    }
+    return SystemProxy(host), nil
+}
+
+func SystemProxy(host string) string {
+    if noProxy(host) {
+        return ""
+    }
+    for _, k := range []string{"ALL_PROXY", "all_proxy", "HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+        v := strings.TrimSpace(os.Getenv(k))
+        if v == "" {
+            continue
+        }
+        if !strings.Contains(v, "://") {
+            v = "http://" + v
+        }
+        if err := ValidateProxyURL(v); err != nil {
+            log.Warn("系统代理不可用，已跳过", "env", k, "proxy", RedactProxyURL(v), "err", err)
+            continue
+        }
+        return normalizeProxyURL(v)
+    }
+    return ""
+}
+
+func normalizeProxyURL(raw string) string {
+    u, err := url.Parse(raw)
+    if err != nil || u.Scheme != "socks5h" {
+        return raw
+    }
+    u.Scheme = "socks5"
+    return u.String()
+}
+
+func noProxy(host string) bool {
+    list := os.Getenv("NO_PROXY")
+    if list == "" {
+        list = os.Getenv("no_proxy")
+    }
+    host = strings.ToLower(host)
+    for _, entry := range strings.Split(list, ",") {
+        entry = strings.ToLower(strings.TrimSpace(entry))
+        if entry == "" {
+            continue
+        }
+        if entry == "*" {
+            return true
+        }
+        if h, _, err := net.SplitHostPort(entry); err == nil {
+            entry = h
+        }
+        if _, cidr, err := net.ParseCIDR(entry); err == nil {
+            if ip := net.ParseIP(host); ip != nil && cidr.Contains(ip) {
+                return true
+            }
+            continue
+        }
+        entry = strings.TrimPrefix(entry, "*")
+        if host == strings.TrimPrefix(entry, ".") || strings.HasSuffix(host, "."+strings.TrimPrefix(entry, ".")) {
+            return true
+        }
+    }
+    return false
+}
+
+func ValidateProxyURL(raw string) error {
+    u, err := url.Parse(raw)
+    if err != nil {
+        return fmt.Errorf("代理地址无效: %w", err)
+    }
+    switch u.Scheme {
+    case "http", "socks5", "socks5h", "ntlm":
+    default:
+        return fmt.Errorf("不支持的代理类型: %s（支持 http、socks5、ntlm）", u.Scheme)
+    }
+    if u.Hostname() == "" {
+        return errors.New("代理地址缺少主机")
+    }
+    return nil
+}
+
+func dialServer(ctx context.Context, p *config.Profile, addr string, timeout time.Duration) (net.Conn, error) {
+    proxyURL, err := ResolveProxy(p)
+    if err != nil {
+        return nil, err
+    }
+    if proxyURL == "" {
+        d := net.Dialer{Timeout: timeout}
+        return d.DialContext(ctx, "tcp", addr)
+    }
+    return dialProxy(ctx, proxyURL, addr, timeout)
+}
+
+func dialProxy(ctx context.Context, raw, addr string, timeout time.Duration) (net.Conn, error) {
+    u, err := url.Parse(raw)
+    if err != nil {
+        return nil, err
+    }
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+    switch u.Scheme {
+    case "socks5", "socks5h":
+        d, err := proxy.FromURL(u, &net.Dialer{Timeout: timeout})
+        if err != nil {
+            return nil, err
+        }
+        if cd, ok := d.(proxy.ContextDialer); ok {
+            return cd.DialContext(ctx, "tcp", addr)
+        }
+        return d.Dial("tcp", addr)
+    case "http":
+        return dialConnect(ctx, u, addr)
+    }
+    return nil, errNoProxyCheck
+}
+
+func dialConnect(ctx context.Context, u *url.URL, addr string) (net.Conn, error) {
+    proxyAddr := u.Host
+    if u.Port() == "" {
+        proxyAddr = net.JoinHostPort(u.Hostname(), "80")
+    }
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "tcp", proxyAddr)
+    if err != nil {
+        return nil, fmt.Errorf("连接代理失败: %w", err)
+    }
+    if deadline, ok := ctx.Deadline(); ok {
+        _ = conn.SetDeadline(deadline)
+    }
+
+    req := &http.Request{
+        Method: http.MethodConnect,
+        URL:    &url.URL{Opaque: addr},
+        Host:   addr,
+        Header: http.Header{},
+    }
+    if u.User != nil {
+        pass, _ := u.User.Password()
+        auth := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + pass))
+        req.Header.Set("Proxy-Authorization", "Basic "+auth)
+    }
+    if err := req.Write(conn); err != nil {
+        _ = conn.Close()
+        return nil, fmt.Errorf("连接代理失败: %w", err)
+    }
+    br := bufio.NewReader(conn)
+    resp, err := http.ReadResponse(br, req)
+    if err != nil {
+        _ = conn.Close()
+        return nil, fmt.Errorf("代理无响应: %w", err)
+    }
+    if resp.StatusCode != http.StatusOK {
+        _ = resp.Body.Close()
+        _ = conn.Close()
+        return nil, fmt.Errorf("代理拒绝连接: %s", resp.Status)
+    }
+    _ = conn.SetDeadline(time.Time{})
+    if br.Buffered() > 0 {
+        return &bufferedConn{Conn: conn, r: br}, nil
+    }
+    return conn, nil
+}
+
+type bufferedConn struct {
+    net.Conn
+    r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+    return c.r.Read(p)
+}
+
+func applyProxy(cfgPath string, p *config.Profile) (string, string, error) {
+    info, err := LoadClientInfo(cfgPath)
+    if err != nil {
+        return "", "", nil
+    }
+    proxyURL := p.ProxyURL
+    if proxyURL == "" {
+        proxyURL = info.ProxyURL
+    }
+    if proxyURL == "" && p.UseSystemProxy {
+        host := p.ServerAddr
+        if host == "" {
+            host = info.ServerAddr
+        }
+        proxyURL = SystemProxy(host)
+    }
+    if proxyURL == "" {
+        return "", "", nil
+    }
+    if err := ValidateProxyURL(proxyURL); err != nil {
+        return "", "", err
+    }
+    proxyURL = normalizeProxyURL(proxyURL)
+    if proxyURL == info.ProxyURL {
+        return "", "", nil
+    }
+    if info.ProxyURL == "" {
+        log.Info("通过环境变量设置代理", "profile", p.Name, "proxy", RedactProxyURL(proxyURL))
+        return proxyURL, "", nil
+    }
+    if f, _ := configFormat(cfgPath); f != "toml" {
+        log.Warn("仅 TOML 配置支持覆盖配置中的代理，已忽略", "profile", p.Name, "config", cfgPath)
+        return "", "", nil
+    }
+    path, err := writeProxyConfig(cfgPath, p, proxyURL)
+    if err != nil {
+        return "", "", err
+    }
+    return proxyURL, path, nil
+}
+
+func writeProxyConfig(cfgPath string, p *config.Profile, proxyURL string) (string, error) {
+    var doc map[string]any
+    if _, err := toml.DecodeFile(cfgPath, &doc); err != nil {
+        return "", fmt.Errorf("无法改写配置中的代理: %w", err)
+    }
+    if v, ok := doc["transport"]; ok {
+        transport, ok := v.(map[string]any)
+        if !ok {
+            return "", errors.New("无法改写配置中的代理: transport 不是表")
+        }
+        transport["proxyURL"] = proxyURL
+    } else {
+        doc["transport"] = map[string]any{"proxyURL": proxyURL}
+    }
+    var out bytes.Buffer
+    if err := toml.NewEncoder(&out).Encode(doc); err != nil {
+        return "", fmt.Errorf("无法改写配置中的代理: %w", err)
+    }
+    var check clientFile
+    if _, err := toml.Decode(out.String(), &check); err != nil || check.Transport.ProxyURL != proxyURL {
+        return "", fmt.Errorf("改写后的配置无效: %v", err)
+    }
+
+    name := ".frpcx-proxy-" + logs.SafeName(p.Name) + ".toml"
+    path := filepath.Join(filepath.Dir(cfgPath), name)
+    if err := os.WriteFile(path, out.Bytes(), 0o600); err != nil {
+        dir, derr := config.CacheDir()
+        if derr != nil {
+            return "", err
+        }
+        log.Warn("无法在配置所在目录写入代理设置，配置中的相对路径可能失效", "profile", p.Name, "dir", filepath.Dir(cfgPath), "err", err)
+        path = filepath.Join(dir, "proxy", name)
+        if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+            return "", err
+        }
+        if err := os.WriteFile(path, out.Bytes(), 0o600); err != nil {
+            return "", err
+        }
+    }
+    log.Info("已写入代理设置", "profile", p.Name, "proxy", RedactProxyURL(proxyURL), "path", path)
+    return path, nil
+}
+
+func RedactProxyURL(raw string) string {
+    u, err := url.Parse(raw)
+    if err != nil || u.User == nil {
+        return raw
+    }
+    u.User = url.User(u.User.Username())
+    return u.String()
+}
@@ -0,0 +1,130 @@
+package frpc
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/BurntSushi/toml"
+
+    "frpcx/internal/config"
+)
+
+func clearProxyEnv(t *testing.T) {
+    t.Helper()
+    for _, k := range []string{"ALL_PROXY", "all_proxy", "HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "NO_PROXY", "no_proxy"} {
+        t.Setenv(k, "")
+    }
+}
+
+func TestSystemProxy(t *testing.T) {
+    clearProxyEnv(t)
+    t.Setenv("HTTPS_PROXY", "https://proxy.example.com:3129")
+    t.Setenv("HTTP_PROXY", "proxy.example.com:3128")
+    if got := SystemProxy("frps.example.com"); got != "http://proxy.example.com:3128" {
+        t.Fatalf("https proxy not skipped: %q", got)
+    }
+
+    t.Setenv("ALL_PROXY", "socks5h://user:pw@127.0.0.1:1080")
+    if got := SystemProxy("frps.example.com"); got != "socks5://user:pw@127.0.0.1:1080" {
+        t.Fatalf("socks5h not mapped: %q", got)
+    }
+
+    t.Setenv("NO_PROXY", ".example.com")
+    if got := SystemProxy("frps.example.com"); got != "" {
+        t.Fatalf("NO_PROXY ignored: %q", got)
+    }
+}
+
+func TestValidateProxyURL(t *testing.T) {
+    for _, ok := range []string{"http://p:3128", "socks5://p:1080", "socks5h://p:1080", "ntlm://p:3128"} {
+        if err := ValidateProxyURL(ok); err != nil {
+            t.Errorf("%s: %v", ok, err)
+        }
+    }
+    for _, bad := range []string{"https://p:3129", "ftp://p", "http://"} {
+        if err := ValidateProxyURL(bad); err == nil {
+            t.Errorf("%s accepted", bad)
+        }
+    }
+}
+
+func TestApplyProxyUsesEnvWhenConfigHasNone(t *testing.T) {
+    useTempConfigDir(t)
+    clearProxyEnv(t)
+    cfgPath := writeTemp(t, "frpc.toml", "serverAddr = \"frps.example.com\"\n")
+    t.Setenv("ALL_PROXY", "socks5h://127.0.0.1:1080")
+    p := &config.Profile{Name: "home", UseSystemProxy: true}
+
+    proxyURL, rewritten, err := applyProxy(cfgPath, p)
+    if err != nil || proxyURL != "socks5://127.0.0.1:1080" || rewritten != "" {
+        t.Fatalf("applyProxy = %q, %q, %v", proxyURL, rewritten, err)
+    }
+    entries, _ := os.ReadDir(filepath.Dir(cfgPath))
+    if len(entries) != 1 {
+        t.Fatalf("unexpected files written: %v", entries)
+    }
+}
+
+func TestApplyProxyOverridesConfig(t *testing.T) {
+    useTempConfigDir(t)
+    clearProxyEnv(t)
+    cfgPath := writeTemp(t, "frpc.toml", `serverAddr = "frps.example.com"
+includes = ["./proxies/*.toml"]
+metadatas.note = """
+[transport]
+"""
+
+[transport]  # proxy settings
+proxyURL = "http://old.example.com:3128"
+tls.certFile = "certs/client.crt"
+
+[[proxies]]
+name = "ssh"
+type = "tcp"
+localPort = 22
+remotePort = 6000
+`)
+    p := &config.Profile{Name: "home", ProxyURL: "socks5h://127.0.0.1:1080"}
+
+    proxyURL, out, err := applyProxy(cfgPath, p)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if proxyURL != "socks5://127.0.0.1:1080" || filepath.Dir(out) != filepath.Dir(cfgPath) {
+        t.Fatalf("applyProxy = %q, %q", proxyURL, out)
+    }
+    info, err := LoadClientInfo(out)
+    if err != nil || info.ProxyURL != proxyURL || info.Transport.CertFile != "certs/client.crt" {
+        t.Fatalf("info = %+v, %v", info, err)
+    }
+    if len(info.Proxies) != 1 || info.Proxies[0].RemotePort != 6000 {
+        t.Fatalf("proxies = %+v", info.Proxies)
+    }
+    var doc struct {
+        Includes  []string          `toml:"includes"`
+        Metadatas map[string]string `toml:"metadatas"`
+    }
+    if _, err := toml.DecodeFile(out, &doc); err != nil || len(doc.Includes) != 1 || doc.Metadatas["note"] != "[transport]\n" {
+        t.Fatalf("doc = %+v, %v", doc, err)
+    }
+
+    inline := writeTemp(t, "inline.toml", "serverAddr = \"frps.example.com\"\ntransport = { proxyURL = \"http://old:3128\", poolCount = 2 }\n")
+    _, out, err = applyProxy(inline, p)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if info, err := LoadClientInfo(out); err != nil || info.ProxyURL != proxyURL || info.Transport.PoolCount != 2 {
+        t.Fatalf("inline info = %+v, %v", info, err)
+    }
+}
+
+func TestFrpcBackendStopRemovesProxyConfig(t *testing.T) {
+    path := writeTemp(t, ".frpcx-proxy-home.toml", "serverAddr = \"x\"\n")
+    b := &frpcBackend{profile: &config.Profile{Name: "home"}, proxyCfg: path}
+    b.Stop(time.Second)
+    if _, err := os.Stat(path); !os.IsNotExist(err) {
+        t.Fatalf("rewritten config left behind: %v", err)
+    }
+}
@@ -0,0 +1,112 @@
+package frpc
+
+import (
+    "context"
+    "time"
+)
+
+type statusReporter interface {
+    reportsStatus() bool
+}
+
+func (m *Manager) Recheck(reason string) {
+    m.mu.Lock()
+    if m.rechecking || m.status != "running" || m.backend == nil {
+        m.mu.Unlock()
+        return
+    }
+    m.rechecking = true
+    b := m.backend
+    ctx := m.runCtx
+    name := m.profileName
+    m.mu.Unlock()
+    defer func() {
+        m.mu.Lock()
+        m.rechecking = false
+        m.mu.Unlock()
+    }()
+
+    p, ok := m.profileByName(name)
+    if !ok || ctx == nil {
+        return
+    }
+    if r, ok := b.(statusReporter); !ok || !r.reportsStatus() {
+        log.Info("网络已变化，无法检查当前会话，重新连接", "profile", name, "reason", reason)
+        m.post(command{kind: cmdRestart, backend: b, msg: reason})
+        return
+    }
+
+    log.Info("网络已变化，立即检查隧道", "profile", name, "reason", reason)
+    timeout := time.Duration(defaultInt(p.HealthTimeoutSec, 3)) * time.Second
+    var err error
+    for i := 0; i < 3; i++ {
+        if i > 0 {
+            select {
+            case <-ctx.Done():
+                return
+            case <-time.After(2 * time.Second):
+            }
+        }
+        hctx, cancel := context.WithTimeout(ctx, timeout)
+        err = b.Health(hctx)
+        cancel()
+        if ctx.Err() != nil {
+            return
+        }
+        if err == nil {
+            if p.RequireStatus {
+                m.setHealth("ok", "")
+            }
+            log.Info("网络变化后隧道检查正常，保持当前连接", "profile", name)
+            return
+        }
+        if p.RequireStatus {
+            m.setHealth("fail", err.Error())
+        }
+    }
+    log.Warn("网络变化后隧道不可用，重新连接", "profile", name, "err", err)
+    m.post(command{kind: cmdRestart, backend: b, msg: reason})
+}
+
+func (m *Manager) handleRestart(c command) {
+    if !m.isCurrent(c.backend, false) {
+        return
+    }
+    m.cancelAttempt()
+    m.mu.Lock()
+    if m.cancel != nil {
+        m.cancel()
+    }
+    b := m.backend
+    grace := m.grace
+    name := m.profileName
+    index := m.lastIndex
+    auto := m.autoSwitch
+    m.backend = nil
+    m.statsLocked(name).Running = false
+    m.status = "starting"
+    m.health = "unknown"
+    m.healthError = ""
+    m.recordLocked()
+    m.mu.Unlock()
+
+    if b != nil {
+        b.Stop(grace)
+    }
+    p, ok := m.profileByName(name)
+    if !ok {
+        m.setError("未找到当前配置")
+        return
+    }
+    m.begin(func(ctx context.Context) {
+        err := m.startProfile(ctx, &p, index)
+        if err == nil || ctx.Err() != nil {
+            return
+        }
+        if !auto {
+            m.setError(err.Error())
+            return
+        }
+        m.startAutoFromIndex(ctx, index+1, name, EventFailover)
+    })
+}
@@ -0,0 +1,143 @@
+package frpc
+
+import (
+    "encoding/json"
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+
+    "frpcx/internal/config"
+)
+
+type RunRecord struct {
+    PID        int       `json:"pid"`
+    Owner      int       `json:"owner"`
+    Profile    string    `json:"profile"`
+    Backend    string    `json:"backend,omitempty"`
+    ConfigPath string    `json:"config_path"`
+    FrpcPath   string    `json:"frpc_path"`
+    StartedAt  time.Time `json:"started_at"`
+}
+
+var runStateMu sync.Mutex
+
+func loadRunState() ([]RunRecord, error) {
+    path, err := config.RuntimeStatePath()
+    if err != nil {
+        return nil, err
+    }
+    b, err := os.ReadFile(path)
+    if err != nil {
+        if errors.Is(err, os.ErrNotExist) {
+            return nil, nil
+        }
+        return nil, err
+    }
+    var out []RunRecord
+    if err := json.Unmarshal(b, &out); err != nil {
+        return nil, err
+    }
+    return out, nil
+}
+
+func saveRunState(records []RunRecord) error {
+    path, err := config.RuntimeStatePath()
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+        return err
+    }
+    b, err := json.MarshalIndent(records, "", "  ")
+    if err != nil {
+        return err
+    }
+    tmp := path + ".tmp"
+    if err := os.WriteFile(tmp, b, 0o600); err != nil {
+        return err
+    }
+    return os.Rename(tmp, path)
+}
+
+func updateRunState(fn func([]RunRecord) []RunRecord) {
+    runStateMu.Lock()
+    defer runStateMu.Unlock()
+    records, err := loadRunState()
+    if err != nil {
+        log.Warn("读取运行状态失败", "err", err)
+    }
+    if err := saveRunState(fn(records)); err != nil {
+        log.Warn("写入运行状态失败", "err", err)
+    }
+}
+
+func recordProcess(rec RunRecord) {
+    updateRunState(func(records []RunRecord) []RunRecord {
+        out := withoutPID(records, rec.PID)
+        return append(out, rec)
+    })
+}
+
+func forgetProcess(pid int) {
+    updateRunState(func(records []RunRecord) []RunRecord {
+        return withoutPID(records, pid)
+    })
+}
+
+func withoutPID(records []RunRecord, pid int) []RunRecord {
+    out := records[:0]
+    for _, r := range records {
+        if r.PID != pid {
+            out = append(out, r)
+        }
+    }
+    return out
+}
+
+func FindOrphans() []RunRecord {
+    var orphans []RunRecord
+    updateRunState(func(records []RunRecord) []RunRecord {
+        out := records[:0]
+        for _, r := range records {
+            if !processAlive(r.PID) || !sameExecutable(r) {
+                continue
+            }
+            out = append(out, r)
+            if r.Owner != os.Getpid() && !processAlive(r.Owner) {
+                orphans = append(orphans, r)
+            }
+        }
+        return out
+    })
+    return orphans
+}
+
+func TerminateOrphan(rec RunRecord, grace time.Duration) error {
+    if !processAlive(rec.PID) {
+        forgetProcess(rec.PID)
+        return nil
+    }
+    p := adoptProcess(rec.PID)
+    p.Stop(grace)
+    forgetProcess(rec.PID)
+    if processAlive(rec.PID) {
+        return errors.New("无法结束残留的 frpc 进程")
+    }
+    log.Info("已结束残留的 frpc 进程", "profile", rec.Profile, "pid", rec.PID)
+    return nil
+}
+
+func sameExecutable(rec RunRecord) bool {
+    exe := processExe(rec.PID)
+    if exe == "" || rec.FrpcPath == "" {
+        return exe != ""
+    }
+    if exe == rec.FrpcPath {
+        return true
+    }
+    base := strings.TrimSuffix(filepath.Base(rec.FrpcPath), ".exe")
+    return strings.HasPrefix(strings.TrimSuffix(filepath.Base(exe), ".exe"), base)
+}
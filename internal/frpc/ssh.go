@@ -0,0 +1,165 @@
+package frpc
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "os"
+    "os/exec"
+    "strconv"
+    "strings"
+    "sync/atomic"
+
+    "frpcx/internal/config"
+)
+
+var sshFailurePatterns = []string{
+    "permission denied",
+    "connection refused",
+    "connection timed out",
+    "could not resolve hostname",
+    "host key verification failed",
+    "remote port forwarding failed",
+    "connection closed by",
+    "no route to host",
+}
+
+type sshBackend struct {
+    procBackend
+    profile  *config.Profile
+    sshPath  string
+    forwards []string
+}
+
+func (b *sshBackend) Prepare(ctx context.Context) error {
+    c := b.profile.SSH
+    if strings.TrimSpace(c.Host) == "" {
+        return errors.New("SSH 主机为空")
+    }
+    if len(c.Forwards) == 0 {
+        return errors.New("没有配置 SSH 远程转发")
+    }
+    forwards := make([]string, 0, len(c.Forwards))
+    for _, f := range c.Forwards {
+        if f.LocalPort <= 0 || f.RemotePort < 0 {
+            return fmt.Errorf("SSH 转发端口无效: %d -> %d", f.RemotePort, f.LocalPort)
+        }
+        forwards = append(forwards, forwardSpec(f))
+    }
+    path, err := exec.LookPath("ssh")
+    if err != nil {
+        return fmt.Errorf("未找到 ssh 命令: %w", err)
+    }
+    b.sshPath = path
+    b.forwards = forwards
+    b.init()
+    return nil
+}
+
+func (b *sshBackend) Start(onLine func(string)) error {
+    c := b.profile.SSH
+    args := []string{
+        "-N", "-T", "-v",
+        "-o", "ExitOnForwardFailure=yes",
+        "-o", "ServerAliveInterval=15",
+        "-o", "ServerAliveCountMax=3",
+        "-o", "BatchMode=yes",
+    }
+    if c.Port > 0 {
+        args = append(args, "-p", strconv.Itoa(c.Port))
+    }
+    if c.IdentityFile != "" {
+        args = append(args, "-i", c.IdentityFile)
+    }
+    for _, opt := range c.Options {
+        args = append(args, "-o", opt)
+    }
+    for _, f := range b.forwards {
+        args = append(args, "-R", f)
+    }
+    args = append(args, b.profile.ExtraArgs...)
+    target := c.Host
+    if c.User != "" {
+        target = c.User + "@" + c.Host
+    }
+    args = append(args, target)
+
+    var established atomic.Int32
+    cmd := exec.Command(b.sshPath, args...)
+    cmd.Env = config.ScrubEnv(os.Environ())
+    proc, err := startProcess(cmd, func(line string) {
+        l := strings.ToLower(line)
+        if strings.Contains(l, "remote forward success") {
+            onLine(line)
+            if int(established.Add(1)) >= len(b.forwards) {
+                b.markReady()
+            }
+            return
+        }
+        for _, p := range sshFailurePatterns {
+            if strings.Contains(l, p) {
+                onLine(line)
+                b.markFailed(errors.New(strings.TrimSpace(line)))
+                return
+            }
+        }
+        if !strings.HasPrefix(line, "debug") {
+            onLine(line)
+        }
+    })
+    if err != nil {
+        return err
+    }
+    b.proc = proc
+    return nil
+}
+
+func (b *sshBackend) Health(ctx context.Context) error {
+    select {
+    case <-b.proc.Done():
+        return errors.New("ssh 已退出")
+    default:
+    }
+    var d net.Dialer
+    for _, f := range b.profile.SSH.Forwards {
+        addr := net.JoinHostPort(localHost(f), strconv.Itoa(f.LocalPort))
+        conn, err := d.DialContext(ctx, "tcp", addr)
+        if err != nil {
+            return fmt.Errorf("本地服务 %s 不可达: %w", addr, err)
+        }
+        _ = conn.Close()
+    }
+    return nil
+}
+
+func (b *sshBackend) Binary() string {
+    return b.sshPath
+}
+
+func (b *sshBackend) ConfigPath() string {
+    return ""
+}
+
+func forwardSpec(f config.SSHForward) string {
+    parts := make([]string, 0, 4)
+    if f.RemoteBind != "" {
+        parts = append(parts, bracketIPv6(f.RemoteBind))
+    }
+    parts = append(parts, strconv.Itoa(f.RemotePort), bracketIPv6(localHost(f)), strconv.Itoa(f.LocalPort))
+    return strings.Join(parts, ":")
+}
+
+func localHost(f config.SSHForward) string {
+    if f.LocalHost == "" {
+        return "127.0.0.1"
+    }
+    return f.LocalHost
+}
+
+func bracketIPv6(host string) string {
+    if strings.Contains(host, ":") && !strings.HasPrefix(host, "[") {
+        return "[" + host + "]"
+    }
+    return host
+}
@@ -0,0 +1,290 @@
+package frpc
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "frpcx/internal/hooks"
+)
+
+type cmdKind int
+
+const (
+    cmdStart cmdKind = iota
+    cmdStop
+    cmdSwitch
+    cmdAdopt
+    cmdFailed
+    cmdFailback
+    cmdRestart
+    cmdPing
+)
+
+type command struct {
+    kind     cmdKind
+    name     string
+    index    int
+    msg      string
+    degraded bool
+    backend  Backend
+    rec      RunRecord
+    done     chan error
+}
+
+type attempt struct {
+    cancel context.CancelFunc
+    done   chan struct{}
+}
+
+func (m *Manager) send(c command) error {
+    c.done = make(chan error, 1)
+    m.cmds <- c
+    return <-c.done
+}
+
+func (m *Manager) post(c command) {
+    m.cmds <- c
+}
+
+func (m *Manager) Ping(timeout time.Duration) error {
+    c := command{kind: cmdPing, done: make(chan error, 1)}
+    t := time.NewTimer(timeout)
+    defer t.Stop()
+    select {
+    case m.cmds <- c:
+    case <-t.C:
+        return errors.New("管理器无响应")
+    }
+    select {
+    case err := <-c.done:
+        return err
+    case <-t.C:
+        return errors.New("管理器无响应")
+    }
+}
+
+func (m *Manager) supervise() {
+    for c := range m.cmds {
+        err := m.handle(c)
+        if c.done != nil {
+            c.done <- err
+        }
+    }
+}
+
+func (m *Manager) handle(c command) error {
+    switch c.kind {
+    case cmdStart:
+        m.mu.Lock()
+        busy := m.status == "starting" || m.status == "running"
+        auto := m.autoSwitch
+        m.mu.Unlock()
+        if busy {
+            return nil
+        }
+        m.cancelAttempt()
+        m.setStarting()
+        log.Info("开始启动")
+        m.begin(func(ctx context.Context) {
+            if auto {
+                m.startAutoFromIndex(ctx, -1, "", EventFailover)
+            } else {
+                m.startSingle(ctx)
+            }
+        })
+    case cmdStop:
+        m.cancelAttempt()
+        m.teardown()
+    case cmdSwitch:
+        return m.switchTo(c.name)
+    case cmdAdopt:
+        return m.adopt(c.rec)
+    case cmdFailed:
+        m.handleFailure(c)
+    case cmdFailback:
+        m.handleFailback(c)
+    case cmdRestart:
+        m.handleRestart(c)
+    }
+    return nil
+}
+
+func (m *Manager) begin(fn func(ctx context.Context)) {
+    ctx, cancel := context.WithCancel(context.Background())
+    a := &attempt{cancel: cancel, done: make(chan struct{})}
+    m.attempt = a
+    go func() {
+        defer close(a.done)
+        defer cancel()
+        fn(ctx)
+    }()
+}
+
+func (m *Manager) cancelAttempt() {
+    a := m.attempt
+    if a == nil {
+        return
+    }
+    m.attempt = nil
+    a.cancel()
+    <-a.done
+}
+
+func (m *Manager) setStarting() {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.status = "starting"
+    m.lastError = ""
+    m.recordLocked()
+}
+
+func (m *Manager) teardown() {
+    m.mu.Lock()
+    if m.cancel != nil {
+        m.cancel()
+    }
+    b := m.backend
+    grace := m.grace
+    m.backend = nil
+    wasActive := m.status == "running" || m.status == "starting"
+    profile := m.profileName
+    if m.profileName != "" {
+        m.statsLocked(m.profileName).Running = false
+    }
+    m.status = "stopped"
+    m.profileName = ""
+    m.health = "stopped"
+    m.healthError = ""
+    m.activeCfg = ""
+    m.activeFrpc = ""
+    m.visitors = nil
+    m.recordLocked()
+    m.mu.Unlock()
+
+    if b != nil {
+        b.Stop(grace)
+    }
+    log.Info("已停止", "profile", profile)
+    if wasActive {
+        if p, ok := m.profileByName(profile); ok {
+            m.runHooks(&p, hooks.PostStop, "", "")
+        }
+        m.emit(Event{Type: EventStopped, Profile: profile})
+    }
+}
+
+func (m *Manager) switchTo(name string) error {
+    m.mu.Lock()
+    profiles := enabledProfiles(m.cfg.Profiles)
+    current := m.profileName
+    m.mu.Unlock()
+
+    idx := -1
+    for i, p := range profiles {
+        if p.Name == name {
+            idx = i
+            break
+        }
+    }
+    if idx < 0 {
+        return fmt.Errorf("配置 %s 不存在或未启用", name)
+    }
+    if current == name {
+        return nil
+    }
+
+    log.Info("切换配置", "from", current, "profile", name)
+    m.cancelAttempt()
+    m.teardown()
+    m.setStarting()
+
+    p := profiles[idx]
+    m.begin(func(ctx context.Context) {
+        if err := m.startProfile(ctx, &p, idx); err != nil && ctx.Err() == nil {
+            m.setError(err.Error())
+        }
+    })
+    return nil
+}
+
+func (m *Manager) isCurrent(b Backend, running bool) bool {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if b == nil || m.backend != b {
+        return false
+    }
+    return !running || m.status == "running"
+}
+
+func (m *Manager) handleFailure(c command) {
+    if !m.isCurrent(c.backend, false) {
+        return
+    }
+    m.cancelAttempt()
+    m.mu.Lock()
+    if m.cancel != nil {
+        m.cancel()
+    }
+    b := m.backend
+    grace := m.grace
+    m.backend = nil
+    name := m.profileName
+    auto := m.autoSwitch
+    next := m.lastIndex + 1
+    m.mu.Unlock()
+
+    if b != nil {
+        b.Stop(grace)
+    }
+    m.setError(c.msg)
+    if c.degraded {
+        m.setHealth("fail", c.msg)
+    }
+    m.recordFailure(name, c.msg)
+    if p, ok := m.profileByName(name); ok {
+        m.runHooks(&p, hooks.OnFailure, c.msg, "")
+    }
+    if !auto {
+        return
+    }
+    m.penalizeFailback(name)
+    m.begin(func(ctx context.Context) {
+        m.startAutoFromIndex(ctx, next, name, EventFailover)
+    })
+}
+
+func (m *Manager) handleFailback(c command) {
+    if !m.isCurrent(c.backend, true) {
+        return
+    }
+    m.cancelAttempt()
+    m.mu.Lock()
+    if m.cancel != nil {
+        m.cancel()
+    }
+    b := m.backend
+    grace := m.grace
+    from := m.profileName
+    m.backend = nil
+    m.statsLocked(from).Running = false
+    m.status = "starting"
+    m.health = "unknown"
+    m.healthError = ""
+    m.failbackTo = c.name
+    m.failbackAt = time.Now()
+    m.recordLocked()
+    m.mu.Unlock()
+
+    log.Info("首选配置已稳定，准备切回", "from", from, "profile", c.name)
+    if b != nil {
+        b.Stop(grace)
+    }
+    if p, ok := m.profileByName(from); ok {
+        m.runHooks(&p, hooks.PostStop, "", "")
+    }
+    m.begin(func(ctx context.Context) {
+        m.startAutoFromIndex(ctx, c.index, from, EventFailback)
+    })
+}
@@ -0,0 +1,304 @@
+package frpc
+
+import (
+    "context"
+    "errors"
+    "runtime"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "frpcx/internal/config"
+    "frpcx/internal/hooks"
+)
+
+type fakeBackend struct {
+    profile  string
+    ready    chan error
+    done     chan struct{}
+    doneOnce sync.Once
+    stopped  atomic.Bool
+    dead     atomic.Bool
+    noStatus bool
+}
+
+func newFakeBackend(profile string) *fakeBackend {
+    return &fakeBackend{profile: profile, ready: make(chan error, 1), done: make(chan struct{})}
+}
+
+func (f *fakeBackend) Prepare(ctx context.Context) error { return nil }
+func (f *fakeBackend) Start(onLine func(string)) error   { return nil }
+func (f *fakeBackend) Done() <-chan struct{}             { return f.done }
+func (f *fakeBackend) Err() error                        { return nil }
+
+func (f *fakeBackend) reportsStatus() bool { return !f.noStatus }
+
+func (f *fakeBackend) Health(ctx context.Context) error {
+    if f.dead.Load() {
+        return errors.New("session lost")
+    }
+    return nil
+}
+
+func (f *fakeBackend) Ready(ctx context.Context) error {
+    select {
+    case err := <-f.ready:
+        return err
+    case <-f.done:
+        return errors.New("exited")
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+func (f *fakeBackend) Stop(grace time.Duration) {
+    f.stopped.Store(true)
+    f.exit()
+}
+
+func (f *fakeBackend) exit() {
+    f.doneOnce.Do(func() { close(f.done) })
+}
+
+type fakeHarness struct {
+    m    *Manager
+    made chan *fakeBackend
+}
+
+func newFakeManager(t *testing.T, auto bool, names ...string) *fakeHarness {
+    t.Helper()
+    useTempConfigDir(t)
+    cfg := &config.AppConfig{AutoSwitch: auto}
+    for _, n := range names {
+        cfg.Profiles = append(cfg.Profiles, config.Profile{Name: n, Enabled: true, StartTimeoutSec: 30})
+    }
+    cfg.ActiveProfile = names[0]
+    h := &fakeHarness{m: NewManager(cfg), made: make(chan *fakeBackend, 8)}
+    h.m.makeBackend = func(p *config.Profile) (Backend, error) {
+        f := newFakeBackend(p.Name)
+        h.made <- f
+        return f, nil
+    }
+    return h
+}
+
+func (h *fakeHarness) next(t *testing.T) *fakeBackend {
+    t.Helper()
+    select {
+    case f := <-h.made:
+        return f
+    case <-time.After(5 * time.Second):
+        t.Fatal("no backend was started")
+        return nil
+    }
+}
+
+func (h *fakeHarness) waitStatus(t *testing.T, status, profile string) {
+    t.Helper()
+    deadline := time.Now().Add(5 * time.Second)
+    for time.Now().Before(deadline) {
+        s := h.m.Status()
+        if s.Status == status && s.ProfileName == profile {
+            return
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+    s := h.m.Status()
+    t.Fatalf("status = %s/%s, want %s/%s", s.Status, s.ProfileName, status, profile)
+}
+
+func TestStaleCommandsKeepAttempt(t *testing.T) {
+    for _, kind := range []cmdKind{cmdFailed, cmdFailback, cmdRestart} {
+        h := newFakeManager(t, true, "a", "b")
+        h.m.Start()
+        a := h.next(t)
+        h.waitStatus(t, "starting", "a")
+
+        stale := newFakeBackend("old")
+        if err := h.m.send(command{kind: kind, backend: stale, msg: "late", index: 1, name: "b"}); err != nil {
+            t.Fatal(err)
+        }
+        if err := h.m.send(command{kind: kind, msg: "late"}); err != nil {
+            t.Fatal(err)
+        }
+        if a.stopped.Load() {
+            t.Fatalf("kind %d: stale command stopped the live backend", kind)
+        }
+        a.ready <- nil
+        h.waitStatus(t, "running", "a")
+        h.m.Stop()
+    }
+}
+
+func TestSwitchDuringStart(t *testing.T) {
+    h := newFakeManager(t, false, "a", "b")
+    h.m.Start()
+    a := h.next(t)
+    h.waitStatus(t, "starting", "a")
+
+    if err := h.m.Switch("b"); err != nil {
+        t.Fatal(err)
+    }
+    if !a.stopped.Load() {
+        t.Fatal("backend of the interrupted start was not stopped")
+    }
+    b := h.next(t)
+    b.ready <- nil
+    h.waitStatus(t, "running", "b")
+
+    h.m.Stop()
+    h.waitStatus(t, "stopped", "")
+    if !b.stopped.Load() {
+        t.Fatal("backend was not stopped")
+    }
+}
+
+func TestStopDuringReady(t *testing.T) {
+    h := newFakeManager(t, false, "a")
+    h.m.Start()
+    a := h.next(t)
+    h.waitStatus(t, "starting", "a")
+
+    h.m.Stop()
+    h.waitStatus(t, "stopped", "")
+    if !a.stopped.Load() {
+        t.Fatal("backend was not stopped")
+    }
+    h.m.mu.Lock()
+    leaked := h.m.backend
+    h.m.mu.Unlock()
+    if leaked != nil {
+        t.Fatal("manager still holds a backend after Stop")
+    }
+
+    h.m.Start()
+    again := h.next(t)
+    again.ready <- nil
+    h.waitStatus(t, "running", "a")
+    h.m.Stop()
+}
+
+func TestExitFailsOverToNextProfile(t *testing.T) {
+    h := newFakeManager(t, true, "a", "b")
+    h.m.Start()
+    a := h.next(t)
+    a.ready <- nil
+    h.waitStatus(t, "running", "a")
+
+    a.exit()
+    b := h.next(t)
+    if b.profile != "b" {
+        t.Fatalf("failover started %s, want b", b.profile)
+    }
+    b.ready <- nil
+    h.waitStatus(t, "running", "b")
+    h.m.Stop()
+}
+
+func TestSubscriberCanCallManager(t *testing.T) {
+    h := newFakeManager(t, false, "a")
+    stopped := make(chan struct{})
+    h.m.Subscribe(func(e Event) {
+        switch e.Type {
+        case EventReady:
+            h.m.Stop()
+        case EventStopped:
+            _ = h.m.Status()
+            close(stopped)
+        }
+    })
+    h.m.Start()
+    h.next(t).ready <- nil
+
+    select {
+    case <-stopped:
+    case <-time.After(5 * time.Second):
+        t.Fatal("manager deadlocked when a subscriber called back into it")
+    }
+    if err := h.m.Ping(time.Second); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestRecheckChecksSessionBeforeRestart(t *testing.T) {
+    h := newFakeManager(t, false, "a")
+    h.m.Start()
+    a := h.next(t)
+    a.ready <- nil
+    h.waitStatus(t, "running", "a")
+
+    h.m.Recheck("test")
+    if a.stopped.Load() {
+        t.Fatal("healthy tunnel was restarted after a network change")
+    }
+
+    a.dead.Store(true)
+    h.m.Recheck("test")
+    if err := h.m.Ping(time.Second); err != nil {
+        t.Fatal(err)
+    }
+    if !a.stopped.Load() {
+        t.Fatal("tunnel with a dead session was not restarted")
+    }
+    h.next(t).ready <- nil
+    h.waitStatus(t, "running", "a")
+    h.m.Stop()
+}
+
+func TestRecheckRestartsWithoutStatus(t *testing.T) {
+    h := newFakeManager(t, false, "a")
+    h.m.Start()
+    a := h.next(t)
+    a.noStatus = true
+    a.ready <- nil
+    h.waitStatus(t, "running", "a")
+
+    h.m.Recheck("test")
+    if err := h.m.Ping(time.Second); err != nil {
+        t.Fatal(err)
+    }
+    if !a.stopped.Load() {
+        t.Fatal("tunnel without a status source was not restarted")
+    }
+    h.m.Stop()
+}
+
+func TestRecheckStopsWaitingOnStop(t *testing.T) {
+    h := newFakeManager(t, false, "a")
+    h.m.Start()
+    a := h.next(t)
+    a.dead.Store(true)
+    a.ready <- nil
+    h.waitStatus(t, "running", "a")
+
+    done := make(chan struct{})
+    go func() {
+        h.m.Recheck("test")
+        close(done)
+    }()
+    time.Sleep(100 * time.Millisecond)
+    h.m.Stop()
+    select {
+    case <-done:
+    case <-time.After(time.Second):
+        t.Fatal("recheck kept waiting after the tunnel was stopped")
+    }
+}
+
+func TestStopDuringPreStartHook(t *testing.T) {
+    if runtime.GOOS == "windows" {
+        t.Skip("uses sh")
+    }
+    h := newFakeManager(t, false, "a")
+    h.m.cfg.Profiles[0].Hooks = []config.Hook{{Event: hooks.PreStart, Command: "sleep 30", TimeoutSec: 60}}
+    h.m.Start()
+    time.Sleep(200 * time.Millisecond)
+
+    start := time.Now()
+    h.m.Stop()
+    if d := time.Since(start); d > 5*time.Second {
+        t.Fatalf("Stop waited %s for the pre-start hook", d)
+    }
+    h.waitStatus(t, "stopped", "")
+}
@@ -0,0 +1,50 @@
+package frpc
+
+import (
+    "bytes"
+    "fmt"
+    "os"
+    "path/filepath"
+
+    "frpcx/internal/config"
+    "frpcx/internal/logs"
+    "frpcx/internal/tmpl"
+)
+
+func RenderedConfigPath(name string) (string, error) {
+    dir, err := config.CacheDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(dir, "rendered", logs.SafeName(name)+".toml"), nil
+}
+
+func RenderProfile(p *config.Profile) (string, error) {
+    out, err := RenderedConfigPath(p.Name)
+    if err != nil {
+        return "", err
+    }
+    t, err := tmpl.ParseFile(p.Template)
+    if err != nil {
+        if _, statErr := os.Stat(out); statErr == nil {
+            log.Warn("读取模板失败，使用上次渲染的配置", "profile", p.Name, "template", p.Template, "err", err)
+            return out, nil
+        }
+        return "", fmt.Errorf("读取模板失败: %w", err)
+    }
+    content, err := t.Render(p.Vars)
+    if err != nil {
+        return "", fmt.Errorf("渲染模板失败: %w", err)
+    }
+    if old, err := os.ReadFile(out); err == nil && bytes.Equal(old, []byte(content)) {
+        return out, nil
+    }
+    if err := os.MkdirAll(filepath.Dir(out), 0o700); err != nil {
+        return "", err
+    }
+    if err := os.WriteFile(out, []byte(content), 0o600); err != nil {
+        return "", err
+    }
+    log.Info("已根据模板生成配置", "profile", p.Name, "template", p.Template, "path", out)
+    return out, nil
+}
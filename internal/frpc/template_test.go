@@ -0,0 +1,57 @@
+package frpc
+
+import (
+    "os"
+    "strings"
+    "testing"
+
+    "frpcx/internal/config"
+)
+
+func TestRenderProfileKeepsVars(t *testing.T) {
+    useTempConfigDir(t)
+    tplPath := writeTemp(t, "team.toml.tmpl", `serverAddr = "{{server:frps.example.com}}"
+[[proxies]]
+name = "{{name}}"
+localPort = {{port:22}}
+`)
+    p := &config.Profile{Name: "home", Template: tplPath, Vars: map[string]string{"name": "alice-ssh", "port": "2222"}}
+
+    out, err := RenderProfile(p)
+    if err != nil {
+        t.Fatal(err)
+    }
+    b, _ := os.ReadFile(out)
+    if !strings.Contains(string(b), `name = "alice-ssh"`) || !strings.Contains(string(b), "localPort = 2222") {
+        t.Fatalf("rendered:\n%s", b)
+    }
+
+    if err := os.WriteFile(tplPath, []byte(`serverAddr = "{{server:frps2.example.com}}"
+serverPort = {{serverPort:7001}}
+[[proxies]]
+name = "{{name}}"
+localPort = {{port:22}}
+`), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    again, err := RenderProfile(p)
+    if err != nil {
+        t.Fatal(err)
+    }
+    b, _ = os.ReadFile(again)
+    for _, want := range []string{`serverAddr = "frps2.example.com"`, "serverPort = 7001", `name = "alice-ssh"`, "localPort = 2222"} {
+        if !strings.Contains(string(b), want) {
+            t.Errorf("missing %q after template change:\n%s", want, b)
+        }
+    }
+    if p.Vars["name"] != "alice-ssh" || p.Vars["port"] != "2222" || len(p.Vars) != 2 {
+        t.Fatalf("vars changed: %v", p.Vars)
+    }
+
+    if err := os.Remove(tplPath); err != nil {
+        t.Fatal(err)
+    }
+    if fallback, err := RenderProfile(p); err != nil || fallback != again {
+        t.Fatalf("missing template: %q, %v", fallback, err)
+    }
+}
@@ -0,0 +1,98 @@
+package frpc
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "fmt"
+    "os"
+)
+
+var TransportProtocols = []string{"tcp", "kcp", "quic", "websocket", "wss"}
+
+type TransportOptions struct {
+    Protocol          string
+    TLSEnable         bool
+    CertFile          string
+    KeyFile           string
+    TrustedCaFile     string
+    ServerName        string
+    TCPMux            bool
+    PoolCount         int
+    HeartbeatInterval int
+    HeartbeatTimeout  int
+}
+
+func DefaultTransport() TransportOptions {
+    return TransportOptions{Protocol: "tcp", TLSEnable: true, TCPMux: true}
+}
+
+func (t TransportOptions) IsDefault() bool {
+    return t == DefaultTransport()
+}
+
+func (t TransportOptions) Validate() error {
+    known := false
+    for _, p := range TransportProtocols {
+        if t.Protocol == p {
+            known = true
+        }
+    }
+    if !known {
+        return fmt.Errorf("不支持的传输协议: %s", t.Protocol)
+    }
+    if t.PoolCount < 0 || t.PoolCount > 100 {
+        return errors.New("连接池大小应在 0 到 100 之间")
+    }
+    if t.HeartbeatInterval < -1 || t.HeartbeatTimeout < -1 {
+        return errors.New("心跳时间无效，-1 表示关闭")
+    }
+    if t.HeartbeatInterval > 0 && t.HeartbeatTimeout > 0 && t.HeartbeatTimeout <= t.HeartbeatInterval {
+        return errors.New("心跳超时应大于心跳间隔")
+    }
+
+    if !t.TLSEnable {
+        if t.CertFile != "" || t.KeyFile != "" || t.TrustedCaFile != "" {
+            return errors.New("已填写证书但未开启 TLS")
+        }
+        return nil
+    }
+    if (t.CertFile == "") != (t.KeyFile == "") {
+        return errors.New("证书文件和私钥文件需要同时填写")
+    }
+    if t.CertFile != "" {
+        if err := fileExists(t.CertFile, "证书文件"); err != nil {
+            return err
+        }
+        if err := fileExists(t.KeyFile, "私钥文件"); err != nil {
+            return err
+        }
+        if _, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile); err != nil {
+            return fmt.Errorf("证书或私钥无法解析: %w", err)
+        }
+    }
+    if t.TrustedCaFile != "" {
+        if err := fileExists(t.TrustedCaFile, "CA 证书"); err != nil {
+            return err
+        }
+        b, err := os.ReadFile(t.TrustedCaFile)
+        if err != nil {
+            return fmt.Errorf("读取 CA 证书失败: %w", err)
+        }
+        if !x509.NewCertPool().AppendCertsFromPEM(b) {
+            return errors.New("CA 证书无法解析，需要 PEM 格式")
+        }
+    }
+    return nil
+}
+
+func fileExists(path, what string) error {
+    st, err := os.Stat(path)
+    if err != nil {
+        return fmt.Errorf("%s不存在: %s", what, path)
+    }
+    if st.IsDir() {
+        return fmt.Errorf("%s不是文件: %s", what, path)
+    }
+    return nil
+}
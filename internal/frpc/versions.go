@@ -0,0 +1,353 @@
+package frpc
+
+import (
+    "archive/tar"
+    "archive/zip"
+    "bufio"
+    "compress/gzip"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "path"
+    "path/filepath"
+    "regexp"
+    "runtime"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "frpcx/internal/config"
+)
+
+const ChecksumsFile = "frp_sha256_checksums.txt"
+
+const maxArchiveSize = 256 << 20
+
+var (
+    archiveVersionRe = regexp.MustCompile(`frp_v?(\d+\.\d+\.\d+)_`)
+    binaryVersionRe  = regexp.MustCompile(`\d+\.\d+\.\d+`)
+    versionNameRe    = regexp.MustCompile(`^[0-9A-Za-z._-]+$`)
+)
+
+type InstalledVersion struct {
+    Version     string
+    Path        string
+    InstalledAt time.Time
+}
+
+func binaryName() string {
+    if runtime.GOOS == "windows" {
+        return "frpc.exe"
+    }
+    return "frpc"
+}
+
+func versionsDir() (string, error) {
+    dir, err := config.CacheDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(dir, "bin"), nil
+}
+
+func ResolveProfileBinary(p *config.Profile) (string, error) {
+    if p.FrpcPath != "" || p.FrpcVersion == "" {
+        return ResolveBinaryPath(p.FrpcPath)
+    }
+    return VersionPath(p.FrpcVersion)
+}
+
+func VersionPath(version string) (string, error) {
+    if !versionNameRe.MatchString(version) {
+        return "", fmt.Errorf("无效的 frpc 版本: %s", version)
+    }
+    dir, err := versionsDir()
+    if err != nil {
+        return "", err
+    }
+    p := filepath.Join(dir, version, binaryName())
+    if _, err := os.Stat(p); err != nil {
+        return "", fmt.Errorf("frpc %s 未安装", version)
+    }
+    return p, nil
+}
+
+func ListVersions() ([]InstalledVersion, error) {
+    dir, err := versionsDir()
+    if err != nil {
+        return nil, err
+    }
+    entries, err := os.ReadDir(dir)
+    if err != nil {
+        if os.IsNotExist(err) {
+            return nil, nil
+        }
+        return nil, err
+    }
+    var out []InstalledVersion
+    for _, e := range entries {
+        if !e.IsDir() {
+            continue
+        }
+        p := filepath.Join(dir, e.Name(), binaryName())
+        st, err := os.Stat(p)
+        if err != nil {
+            continue
+        }
+        out = append(out, InstalledVersion{Version: e.Name(), Path: p, InstalledAt: st.ModTime()})
+    }
+    sort.Slice(out, func(i, j int) bool {
+        return compareVersions(out[i].Version, out[j].Version) > 0
+    })
+    return out, nil
+}
+
+func RemoveVersion(version string) error {
+    p, err := VersionPath(version)
+    if err != nil {
+        return err
+    }
+    if err := os.RemoveAll(filepath.Dir(p)); err != nil {
+        return err
+    }
+    log.Info("已删除 frpc 版本", "version", version)
+    return nil
+}
+
+func ReleaseURL(version string) string {
+    version = strings.TrimPrefix(version, "v")
+    ext := ".tar.gz"
+    if runtime.GOOS == "windows" {
+        ext = ".zip"
+    }
+    return fmt.Sprintf("https://github.com/fatedier/frp/releases/download/v%s/frp_%s_%s_%s%s", version, version, runtime.GOOS, runtime.GOARCH, ext)
+}
+
+func InstallFromFile(archivePath, checksumsPath string) (InstalledVersion, error) {
+    if checksumsPath == "" {
+        checksumsPath = filepath.Join(filepath.Dir(archivePath), ChecksumsFile)
+    }
+    sums, err := os.ReadFile(checksumsPath)
+    if err != nil {
+        return InstalledVersion{}, fmt.Errorf("读取校验文件失败: %w", err)
+    }
+    f, err := os.Open(archivePath)
+    if err != nil {
+        return InstalledVersion{}, err
+    }
+    defer f.Close()
+    return install(f, filepath.Base(archivePath), sums)
+}
+
+func InstallFromURL(ctx context.Context, archiveURL, checksumsURL string) (InstalledVersion, error) {
+    u, err := url.Parse(archiveURL)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+        return InstalledVersion{}, fmt.Errorf("无效的下载地址: %s", archiveURL)
+    }
+    name := path.Base(u.Path)
+    if checksumsURL == "" {
+        c := *u
+        c.Path = path.Join(path.Dir(u.Path), ChecksumsFile)
+        c.RawQuery = ""
+        checksumsURL = c.String()
+    }
+
+    log.Info("下载 frpc", "url", archiveURL)
+    sumsBody, err := download(ctx, checksumsURL)
+    if err != nil {
+        return InstalledVersion{}, fmt.Errorf("下载校验文件失败: %w", err)
+    }
+    sums, err := io.ReadAll(io.LimitReader(sumsBody, 1<<20))
+    sumsBody.Close()
+    if err != nil {
+        return InstalledVersion{}, fmt.Errorf("下载校验文件失败: %w", err)
+    }
+
+    body, err := download(ctx, archiveURL)
+    if err != nil {
+        return InstalledVersion{}, fmt.Errorf("下载 frpc 失败: %w", err)
+    }
+    defer body.Close()
+    return install(body, name, sums)
+}
+
+func download(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+    if err != nil {
+        return nil, err
+    }
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    if resp.StatusCode != http.StatusOK {
+        resp.Body.Close()
+        return nil, fmt.Errorf("%s: HTTP %d", rawURL, resp.StatusCode)
+    }
+    return resp.Body, nil
+}
+
+func install(r io.Reader, name string, sums []byte) (InstalledVersion, error) {
+    want, err := lookupChecksum(sums, name)
+    if err != nil {
+        return InstalledVersion{}, err
+    }
+
+    dir, err := versionsDir()
+    if err != nil {
+        return InstalledVersion{}, err
+    }
+    if err := os.MkdirAll(dir, 0o700); err != nil {
+        return InstalledVersion{}, err
+    }
+
+    tmp, err := os.CreateTemp(dir, ".archive-*")
+    if err != nil {
+        return InstalledVersion{}, err
+    }
+    defer os.Remove(tmp.Name())
+    defer tmp.Close()
+
+    h := sha256.New()
+    n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, maxArchiveSize+1))
+    if err != nil {
+        return InstalledVersion{}, err
+    }
+    if n > maxArchiveSize {
+        return InstalledVersion{}, errors.New("安装包过大")
+    }
+    if got := hex.EncodeToString(h.Sum(nil)); got != want {
+        return InstalledVersion{}, fmt.Errorf("SHA-256 校验失败: 期望 %s，实际 %s", want, got)
+    }
+
+    stage, err := os.MkdirTemp(dir, ".install-*")
+    if err != nil {
+        return InstalledVersion{}, err
+    }
+    defer os.RemoveAll(stage)
+    bin := filepath.Join(stage, binaryName())
+    if err := extractBinary(tmp, n, name, bin); err != nil {
+        return InstalledVersion{}, err
+    }
+
+    version := ""
+    if m := archiveVersionRe.FindStringSubmatch(name); m != nil {
+        version = m[1]
+    } else if out, err := BinaryVersion(bin); err == nil {
+        version = binaryVersionRe.FindString(out)
+    }
+    if version == "" {
+        return InstalledVersion{}, fmt.Errorf("无法识别 %s 的版本", name)
+    }
+
+    dst := filepath.Join(dir, version)
+    if err := os.RemoveAll(dst); err != nil {
+        return InstalledVersion{}, err
+    }
+    if err := os.Rename(stage, dst); err != nil {
+        return InstalledVersion{}, err
+    }
+    _ = os.Chmod(dst, 0o700)
+    log.Info("已安装 frpc", "version", version, "archive", name)
+    return InstalledVersion{Version: version, Path: filepath.Join(dst, binaryName()), InstalledAt: time.Now()}, nil
+}
+
+func lookupChecksum(sums []byte, name string) (string, error) {
+    sc := bufio.NewScanner(strings.NewReader(string(sums)))
+    for sc.Scan() {
+        fields := strings.Fields(sc.Text())
+        if len(fields) < 2 {
+            continue
+        }
+        if strings.TrimPrefix(fields[len(fields)-1], "*") == name {
+            return strings.ToLower(fields[0]), nil
+        }
+    }
+    return "", fmt.Errorf("校验文件中没有 %s", name)
+}
+
+func extractBinary(f *os.File, size int64, name, dst string) error {
+    switch {
+    case strings.HasSuffix(name, ".zip"):
+        zr, err := zip.NewReader(f, size)
+        if err != nil {
+            return err
+        }
+        for _, zf := range zr.File {
+            if path.Base(zf.Name) != binaryName() || zf.FileInfo().IsDir() {
+                continue
+            }
+            rc, err := zf.Open()
+            if err != nil {
+                return err
+            }
+            defer rc.Close()
+            return writeBinary(rc, dst)
+        }
+    case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+        if _, err := f.Seek(0, io.SeekStart); err != nil {
+            return err
+        }
+        gz, err := gzip.NewReader(f)
+        if err != nil {
+            return err
+        }
+        defer gz.Close()
+        tr := tar.NewReader(gz)
+        for {
+            hdr, err := tr.Next()
+            if err == io.EOF {
+                break
+            }
+            if err != nil {
+                return err
+            }
+            if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == binaryName() {
+                return writeBinary(tr, dst)
+            }
+        }
+    default:
+        return fmt.Errorf("不支持的安装包格式: %s", name)
+    }
+    return fmt.Errorf("安装包中没有 %s", binaryName())
+}
+
+func writeBinary(r io.Reader, dst string) error {
+    out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o700)
+    if err != nil {
+        return err
+    }
+    if _, err := io.Copy(out, io.LimitReader(r, maxArchiveSize)); err != nil {
+        out.Close()
+        return err
+    }
+    return out.Close()
+}
+
+func compareVersions(a, b string) int {
+    pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+    pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+    for i := 0; i < len(pa) || i < len(pb); i++ {
+        var x, y int
+        if i < len(pa) {
+            x, _ = strconv.Atoi(pa[i])
+        }
+        if i < len(pb) {
+            y, _ = strconv.Atoi(pb[i])
+        }
+        if x != y {
+            if x > y {
+                return 1
+            }
+            return -1
+        }
+    }
+    return strings.Compare(a, b)
+}
@@ -0,0 +1,188 @@
+package frpc
+
+import (
+    "archive/tar"
+    "bytes"
+    "compress/gzip"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+const testArchive = "frp_0.61.0_linux_amd64.tar.gz"
+
+func buildArchive(t *testing.T, bin []byte) []byte {
+    t.Helper()
+    var buf bytes.Buffer
+    gz := gzip.NewWriter(&buf)
+    tw := tar.NewWriter(gz)
+    files := []struct {
+        name string
+        data []byte
+    }{
+        {"frp_0.61.0_linux_amd64/LICENSE", []byte("license")},
+        {"frp_0.61.0_linux_amd64/" + binaryName(), bin},
+    }
+    for _, f := range files {
+        hdr := &tar.Header{Name: f.name, Mode: 0o755, Size: int64(len(f.data)), Typeflag: tar.TypeReg}
+        if err := tw.WriteHeader(hdr); err != nil {
+            t.Fatal(err)
+        }
+        if _, err := tw.Write(f.data); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := tw.Close(); err != nil {
+        t.Fatal(err)
+    }
+    if err := gz.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return buf.Bytes()
+}
+
+func checksumLine(data []byte, name string) string {
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:]) + "  " + name + "\n"
+}
+
+func useTempConfigDir(t *testing.T) {
+    t.Helper()
+    dir := t.TempDir()
+    t.Setenv("XDG_CONFIG_HOME", dir)
+    t.Setenv("HOME", dir)
+    t.Setenv("AppData", dir)
+}
+
+func TestInstallFromFile(t *testing.T) {
+    useTempConfigDir(t)
+    archive := buildArchive(t, []byte("#!/bin/sh\necho frpc\n"))
+    dir := t.TempDir()
+    archivePath := filepath.Join(dir, testArchive)
+    if err := os.WriteFile(archivePath, archive, 0o600); err != nil {
+        t.Fatal(err)
+    }
+    sums := checksumLine([]byte("other"), "frp_0.61.0_windows_amd64.zip") + checksumLine(archive, testArchive)
+    if err := os.WriteFile(filepath.Join(dir, ChecksumsFile), []byte(sums), 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    v, err := InstallFromFile(archivePath, "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if v.Version != "0.61.0" {
+        t.Fatalf("version = %q", v.Version)
+    }
+    data, err := os.ReadFile(v.Path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if string(data) != "#!/bin/sh\necho frpc\n" {
+        t.Fatalf("binary = %q", data)
+    }
+    p, err := VersionPath("0.61.0")
+    if err != nil || p != v.Path {
+        t.Fatalf("VersionPath = %q, %v", p, err)
+    }
+    list, err := ListVersions()
+    if err != nil || len(list) != 1 || list[0].Version != "0.61.0" {
+        t.Fatalf("ListVersions = %+v, %v", list, err)
+    }
+    if err := RemoveVersion("0.61.0"); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := VersionPath("0.61.0"); err == nil {
+        t.Fatal("version still installed after RemoveVersion")
+    }
+}
+
+func TestInstallChecksumMismatch(t *testing.T) {
+    useTempConfigDir(t)
+    archive := buildArchive(t, []byte("frpc"))
+    sums := checksumLine([]byte("tampered"), testArchive)
+
+    _, err := install(bytes.NewReader(archive), testArchive, []byte(sums))
+    if err == nil || !strings.Contains(err.Error(), "SHA-256 校验失败") {
+        t.Fatalf("err = %v", err)
+    }
+    if list, _ := ListVersions(); len(list) != 0 {
+        t.Fatalf("installed despite mismatch: %+v", list)
+    }
+}
+
+func TestInstallMissingChecksum(t *testing.T) {
+    useTempConfigDir(t)
+    archive := buildArchive(t, []byte("frpc"))
+    sums := checksumLine(archive, "frp_0.60.0_linux_amd64.tar.gz")
+
+    if _, err := install(bytes.NewReader(archive), testArchive, []byte(sums)); err == nil {
+        t.Fatal("install without a matching checksum entry succeeded")
+    }
+}
+
+func TestInstallFromURL(t *testing.T) {
+    useTempConfigDir(t)
+    archive := buildArchive(t, []byte("frpc"))
+    mux := http.NewServeMux()
+    mux.HandleFunc("/v0.61.0/"+testArchive, func(w http.ResponseWriter, r *http.Request) {
+        _, _ = w.Write(archive)
+    })
+    mux.HandleFunc("/v0.61.0/"+ChecksumsFile, func(w http.ResponseWriter, r *http.Request) {
+        _, _ = w.Write([]byte(checksumLine(archive, "*"+testArchive)))
+    })
+    srv := httptest.NewServer(mux)
+    defer srv.Close()
+
+    v, err := InstallFromURL(context.Background(), srv.URL+"/v0.61.0/"+testArchive, "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if v.Version != "0.61.0" {
+        t.Fatalf("version = %q", v.Version)
+    }
+
+    if _, err := InstallFromURL(context.Background(), srv.URL+"/v0.62.0/"+testArchive, ""); err == nil {
+        t.Fatal("install with missing checksums file succeeded")
+    }
+    if _, err := InstallFromURL(context.Background(), "file:///tmp/"+testArchive, ""); err == nil {
+        t.Fatal("file:// URL accepted")
+    }
+}
+
+func TestLookupChecksum(t *testing.T) {
+    sums := []byte("ABCDEF  frp_a.tar.gz\n\nbadline\n012345 *frp_b.zip\n")
+    if got, err := lookupChecksum(sums, "frp_a.tar.gz"); err != nil || got != "abcdef" {
+        t.Fatalf("frp_a = %q, %v", got, err)
+    }
+    if got, err := lookupChecksum(sums, "frp_b.zip"); err != nil || got != "012345" {
+        t.Fatalf("frp_b = %q, %v", got, err)
+    }
+    if _, err := lookupChecksum(sums, "frp_c.zip"); err == nil {
+        t.Fatal("missing entry found")
+    }
+}
+
+func TestCompareVersions(t *testing.T) {
+    cases := []struct {
+        a, b string
+        want int
+    }{
+        {"0.61.0", "0.61.0", 0},
+        {"0.61.0", "0.9.0", 1},
+        {"v0.52.3", "0.61.0", -1},
+        {"1.0", "0.99.99", 1},
+        {"0.61", "0.61.1", -1},
+    }
+    for _, c := range cases {
+        if got := compareVersions(c.a, c.b); got != c.want {
+            t.Errorf("compareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+        }
+    }
+}
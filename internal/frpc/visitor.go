@@ -0,0 +1,334 @@
+package frpc
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "frpcx/internal/config"
+)
+
+var VisitorTypes = []string{"stcp", "xtcp", "sudp"}
+
+type VisitorInfo struct {
+    Name              string `toml:"name"`
+    Type              string `toml:"type"`
+    ServerUser        string `toml:"serverUser"`
+    ServerName        string `toml:"serverName"`
+    SecretKey         string `toml:"secretKey"`
+    BindAddr          string `toml:"bindAddr"`
+    BindPort          int    `toml:"bindPort"`
+    Protocol          string `toml:"protocol"`
+    KeepTunnelOpen    bool   `toml:"keepTunnelOpen"`
+    FallbackTo        string `toml:"fallbackTo"`
+    FallbackTimeoutMs int    `toml:"fallbackTimeoutMs"`
+
+    user string
+}
+
+type VisitorStatus struct {
+    Name       string
+    Type       string
+    ServerName string
+    Bind       string
+    Listening  bool
+    Error      string
+}
+
+func (v VisitorInfo) Bind() string {
+    addr := v.BindAddr
+    if addr == "" {
+        addr = "127.0.0.1"
+    }
+    return net.JoinHostPort(addr, strconv.Itoa(v.BindPort))
+}
+
+func (v VisitorInfo) frpName() string {
+    if v.user == "" {
+        return v.Name
+    }
+    return v.user + "." + v.Name
+}
+
+func (v VisitorInfo) network() string {
+    if v.Type == "sudp" {
+        return "udp"
+    }
+    return "tcp"
+}
+
+func (v VisitorInfo) Validate() error {
+    if v.Name == "" {
+        return errors.New("访问者名称不能为空")
+    }
+    known := false
+    for _, t := range VisitorTypes {
+        if v.Type == t {
+            known = true
+        }
+    }
+    if !known {
+        return fmt.Errorf("访问者 %s 的类型不支持: %s", v.Name, v.Type)
+    }
+    if v.ServerName == "" {
+        return fmt.Errorf("访问者 %s 未填写要访问的代理名称（serverName）", v.Name)
+    }
+    if v.BindPort == 0 || v.BindPort > 65535 || v.BindPort < -1 {
+        return fmt.Errorf("访问者 %s 的本地端口无效: %d", v.Name, v.BindPort)
+    }
+    if v.BindPort == -1 && v.Type != "xtcp" {
+        return fmt.Errorf("只有 xtcp 访问者可以不监听本地端口: %s", v.Name)
+    }
+    if v.Type != "xtcp" && (v.Protocol != "" || v.KeepTunnelOpen || v.FallbackTo != "" || v.FallbackTimeoutMs != 0) {
+        return fmt.Errorf("访问者 %s: 协议、保持隧道与回退设置只适用于 xtcp", v.Name)
+    }
+    if v.Protocol != "" && v.Protocol != "quic" && v.Protocol != "kcp" {
+        return fmt.Errorf("访问者 %s 的 xtcp 协议无效: %s", v.Name, v.Protocol)
+    }
+    if v.FallbackTimeoutMs < 0 {
+        return fmt.Errorf("访问者 %s 的回退超时无效", v.Name)
+    }
+    return nil
+}
+
+func ValidateVisitors(vs []VisitorInfo) error {
+    names := map[string]VisitorInfo{}
+    binds := map[string]string{}
+    for _, v := range vs {
+        if err := v.Validate(); err != nil {
+            return err
+        }
+        if _, ok := names[v.Name]; ok {
+            return fmt.Errorf("访问者名称重复: %s", v.Name)
+        }
+        names[v.Name] = v
+        if v.BindPort > 0 {
+            key := v.network() + "/" + strconv.Itoa(v.BindPort)
+            if other, ok := binds[key]; ok {
+                return fmt.Errorf("访问者 %s 与 %s 使用了相同的本地端口 %d", v.Name, other, v.BindPort)
+            }
+            binds[key] = v.Name
+        }
+    }
+    for _, v := range vs {
+        if v.FallbackTo == "" {
+            continue
+        }
+        target, ok := names[v.FallbackTo]
+        if !ok {
+            return fmt.Errorf("访问者 %s 的回退目标不存在: %s", v.Name, v.FallbackTo)
+        }
+        if target.Type != "stcp" {
+            return fmt.Errorf("访问者 %s 的回退目标需要是 stcp 访问者", v.Name)
+        }
+    }
+    return nil
+}
+
+func checkVisitorBinds(vs []VisitorInfo) error {
+    for _, v := range vs {
+        if v.BindPort <= 0 {
+            continue
+        }
+        if v.network() == "udp" {
+            c, err := net.ListenPacket("udp", v.Bind())
+            if err != nil {
+                return fmt.Errorf("访问者 %s 的本地端口 %s 已被占用", v.Name, v.Bind())
+            }
+            _ = c.Close()
+            continue
+        }
+        l, err := net.Listen("tcp", v.Bind())
+        if err != nil {
+            return fmt.Errorf("访问者 %s 的本地端口 %s 已被占用", v.Name, v.Bind())
+        }
+        _ = l.Close()
+    }
+    return nil
+}
+
+type visitorLog struct {
+    mu      sync.Mutex
+    retry   string
+    pending []string
+    started map[string]bool
+    errs    map[string]string
+}
+
+func newVisitorLog() *visitorLog {
+    return &visitorLog{started: map[string]bool{}, errs: map[string]string{}}
+}
+
+func (l *visitorLog) observe(line string) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if _, rest, ok := strings.Cut(line, "try to start visitor ["); ok {
+        l.retry, _, _ = strings.Cut(rest, "]")
+        return
+    }
+    if _, rest, ok := strings.Cut(line, "visitor added: ["); ok {
+        rest, _, _ = strings.Cut(rest, "]")
+        for i, name := range strings.Fields(rest) {
+            if i < len(l.pending) {
+                l.set(name, l.pending[i])
+            }
+        }
+        l.pending = nil
+        return
+    }
+    result := ""
+    if strings.Contains(line, "start visitor success") {
+        result = "ok"
+    } else if _, msg, ok := strings.Cut(line, "start error: "); ok {
+        result = "启动失败: " + strings.TrimSpace(msg)
+    } else {
+        return
+    }
+    if l.retry != "" {
+        l.set(l.retry, result)
+        l.retry = ""
+        return
+    }
+    l.pending = append(l.pending, result)
+}
+
+func (l *visitorLog) set(name, result string) {
+    if result == "ok" {
+        l.started[name] = true
+        delete(l.errs, name)
+        return
+    }
+    l.started[name] = false
+    l.errs[name] = result
+}
+
+func (l *visitorLog) state(name string) (bool, string) {
+    if l == nil {
+        return false, ""
+    }
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.started[name], l.errs[name]
+}
+
+func (v VisitorInfo) dialAddr() string {
+    host := v.BindAddr
+    if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+        host = "127.0.0.1"
+        if ip != nil && ip.To4() == nil {
+            host = "::1"
+        }
+    }
+    return net.JoinHostPort(host, strconv.Itoa(v.BindPort))
+}
+
+func probeVisitor(v VisitorInfo, vl *visitorLog) error {
+    if v.network() == "udp" {
+        started, msg := vl.state(v.frpName())
+        if msg != "" {
+            return errors.New(msg)
+        }
+        if !started {
+            return fmt.Errorf("%s 尚未启动", v.Bind())
+        }
+        return nil
+    }
+    c, err := net.DialTimeout("tcp", v.dialAddr(), time.Second)
+    if err != nil {
+        return fmt.Errorf("%s 未监听", v.Bind())
+    }
+    _ = c.Close()
+    return nil
+}
+
+func probeVisitors(vs []VisitorInfo, vl *visitorLog) ([]VisitorStatus, error) {
+    out := make([]VisitorStatus, 0, len(vs))
+    var firstErr error
+    for _, v := range vs {
+        st := VisitorStatus{Name: v.Name, Type: v.Type, ServerName: v.ServerName, Bind: v.Bind()}
+        if v.BindPort > 0 {
+            if err := probeVisitor(v, vl); err != nil {
+                st.Error = err.Error()
+                if firstErr == nil {
+                    firstErr = fmt.Errorf("访问者 %s: %w", v.Name, err)
+                }
+            } else {
+                st.Listening = true
+            }
+        } else {
+            st.Bind = ""
+        }
+        out = append(out, st)
+    }
+    return out, firstErr
+}
+
+func loadVisitors(cfgPath string) []VisitorInfo {
+    if cfgPath == "" {
+        return nil
+    }
+    info, err := LoadClientInfo(cfgPath)
+    if err != nil {
+        return nil
+    }
+    vs := info.Visitors
+    for i := range vs {
+        vs[i].user = info.User
+    }
+    return vs
+}
+
+func (m *Manager) waitForVisitors(ctx context.Context, p *config.Profile, vs []VisitorInfo, vl *visitorLog) error {
+    if len(vs) == 0 {
+        return nil
+    }
+    timeout := time.Duration(defaultInt(p.StartTimeoutSec, 8)) * time.Second
+    deadline := time.Now().Add(timeout)
+    for {
+        st, err := probeVisitors(vs, vl)
+        m.setVisitors(st)
+        if err == nil {
+            log.Info("访问者已就绪", "profile", p.Name, "count", len(vs))
+            return nil
+        }
+        if time.Now().After(deadline) {
+            return err
+        }
+        select {
+        case <-ctx.Done():
+            return ctx.Err()
+        case <-time.After(300 * time.Millisecond):
+        }
+    }
+}
+
+func (m *Manager) monitorVisitors(ctx context.Context, p *config.Profile, vs []VisitorInfo, vl *visitorLog) {
+    ticker := time.NewTicker(10 * time.Second)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+        st, err := probeVisitors(vs, vl)
+        if ctx.Err() != nil {
+            return
+        }
+        if err != nil {
+            log.Warn("访问者端口检查失败", "profile", p.Name, "err", err)
+        }
+        m.setVisitors(st)
+    }
+}
+
+func (m *Manager) setVisitors(st []VisitorStatus) {
+    m.mu.Lock()
+    m.visitors = st
+    m.mu.Unlock()
+}
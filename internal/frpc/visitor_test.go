@@ -0,0 +1,88 @@
+package frpc
+
+import (
+    "net"
+    "strings"
+    "testing"
+)
+
+func TestProbeVisitorTCP(t *testing.T) {
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    port := ln.Addr().(*net.TCPAddr).Port
+    go func() {
+        for {
+            c, err := ln.Accept()
+            if err != nil {
+                return
+            }
+            c.Close()
+        }
+    }()
+
+    for _, addr := range []string{"", "127.0.0.1", "0.0.0.0"} {
+        v := VisitorInfo{Name: "ssh", Type: "stcp", BindAddr: addr, BindPort: port}
+        if err := probeVisitor(v, nil); err != nil {
+            t.Fatalf("bindAddr %q: %v", addr, err)
+        }
+    }
+
+    ln.Close()
+    v := VisitorInfo{Name: "ssh", Type: "stcp", BindPort: port}
+    if err := probeVisitor(v, nil); err == nil {
+        t.Fatal("closed port reported as listening")
+    }
+    again, err := net.Listen("tcp", v.Bind())
+    if err != nil {
+        t.Fatalf("probe held the port: %v", err)
+    }
+    again.Close()
+}
+
+func TestProbeVisitorUDP(t *testing.T) {
+    c, err := net.ListenPacket("udp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer c.Close()
+    port := c.LocalAddr().(*net.UDPAddr).Port
+    v := VisitorInfo{Name: "dns", Type: "sudp", BindPort: port, user: "alice"}
+
+    vl := newVisitorLog()
+    if err := probeVisitor(v, vl); err == nil {
+        t.Fatal("port held by another process counted as a started visitor")
+    }
+    vl.observe("2024-01-01 00:00:00.000 [I] [visitor/visitor_manager.go:121] [abc123] start visitor success")
+    vl.observe("2024-01-01 00:00:00.000 [I] [visitor/visitor_manager.go:172] [abc123] visitor added: [alice.dns]")
+    if err := probeVisitor(v, vl); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestVisitorLog(t *testing.T) {
+    vl := newVisitorLog()
+    for _, line := range []string{
+        "[W] [visitor/visitor_manager.go:118] [abc] start error: listen udp 127.0.0.1:53: bind: address already in use",
+        "[I] [visitor/visitor_manager.go:121] [abc] start visitor success",
+        "[I] [visitor/visitor_manager.go:172] [abc] visitor added: [dns ssh]",
+    } {
+        vl.observe(line)
+    }
+    if ok, msg := vl.state("dns"); ok || !strings.Contains(msg, "address already in use") {
+        t.Fatalf("dns = %v, %q", ok, msg)
+    }
+    if ok, msg := vl.state("ssh"); !ok || msg != "" {
+        t.Fatalf("ssh = %v, %q", ok, msg)
+    }
+
+    vl.observe("[I] [visitor/visitor_manager.go:89] [abc] try to start visitor [dns]")
+    vl.observe("[I] [visitor/visitor_manager.go:121] [abc] start visitor success")
+    if ok, msg := vl.state("dns"); !ok || msg != "" {
+        t.Fatalf("dns after retry = %v, %q", ok, msg)
+    }
+    if ok, _ := vl.state("other"); ok {
+        t.Fatal("unknown visitor reported as started")
+    }
+}
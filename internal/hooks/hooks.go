@@ -0,0 +1,165 @@
+package hooks
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log/slog"
+    "net/http"
+    "os"
+    "os/exec"
+    "runtime"
+    "strings"
+    "time"
+
+    "frpcx/internal/config"
+    "frpcx/internal/logs"
+)
+
+var log = logs.For("hooks")
+
+const (
+    PreStart   = "pre-start"
+    PostReady  = "post-ready"
+    OnFailure  = "on-failure"
+    PostStop   = "post-stop"
+    OnFailover = "on-failover"
+)
+
+var Events = []string{PreStart, PostReady, OnFailure, PostStop, OnFailover}
+
+type Event struct {
+    Event      string    `json:"event"`
+    Time       time.Time `json:"time"`
+    Profile    string    `json:"profile"`
+    From       string    `json:"from,omitempty"`
+    Message    string    `json:"message,omitempty"`
+    ConfigPath string    `json:"config_path,omitempty"`
+}
+
+func (e Event) env() []string {
+    return []string{
+        "FRPCX_EVENT=" + e.Event,
+        "FRPCX_TIME=" + e.Time.Format(time.RFC3339),
+        "FRPCX_PROFILE=" + e.Profile,
+        "FRPCX_FROM_PROFILE=" + e.From,
+        "FRPCX_MESSAGE=" + e.Message,
+        "FRPCX_CONFIG_PATH=" + e.ConfigPath,
+    }
+}
+
+func Run(ctx context.Context, list []config.Hook, e Event) error {
+    if e.Time.IsZero() {
+        e.Time = time.Now()
+    }
+    var errs []error
+    for _, h := range list {
+        if h.Event != e.Event {
+            continue
+        }
+        if err := runOne(ctx, h, e); err != nil {
+            errs = append(errs, err)
+        }
+    }
+    return errors.Join(errs...)
+}
+
+func RunAsync(list []config.Hook, e Event) {
+    if !Has(list, e.Event) {
+        return
+    }
+    go func() {
+        _ = Run(context.Background(), list, e)
+    }()
+}
+
+func Has(list []config.Hook, event string) bool {
+    for _, h := range list {
+        if h.Event == event {
+            return true
+        }
+    }
+    return false
+}
+
+func runOne(ctx context.Context, h config.Hook, e Event) error {
+    timeout := time.Duration(defaultInt(h.TimeoutSec, 10)) * time.Second
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    l := log.With("profile", e.Profile, "event", e.Event)
+    start := time.Now()
+    var err error
+    switch {
+    case h.Command != "":
+        err = runCommand(ctx, l, h.Command, e)
+    case h.URL != "":
+        err = postJSON(ctx, l, h.URL, e)
+    default:
+        err = errors.New("钩子未配置命令或 URL")
+    }
+    if ctx.Err() == context.DeadlineExceeded {
+        err = fmt.Errorf("钩子超时（%s）", timeout)
+    }
+    if err != nil {
+        l.Error("钩子执行失败", "err", err, "elapsed", time.Since(start).Round(time.Millisecond))
+        return fmt.Errorf("%s 钩子失败: %w", e.Event, err)
+    }
+    l.Info("钩子执行完成", "elapsed", time.Since(start).Round(time.Millisecond))
+    return nil
+}
+
+func runCommand(ctx context.Context, l *slog.Logger, command string, e Event) error {
+    var cmd *exec.Cmd
+    if runtime.GOOS == "windows" {
+        cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+    } else {
+        cmd = exec.CommandContext(ctx, "sh", "-c", command)
+    }
+    cmd.Env = append(config.ScrubEnv(os.Environ()), e.env()...)
+    cmd.WaitDelay = time.Second
+    out, err := cmd.CombinedOutput()
+    sc := bufio.NewScanner(bytes.NewReader(out))
+    for sc.Scan() {
+        if line := strings.TrimSpace(sc.Text()); line != "" {
+            l.Info("钩子输出", "line", line)
+        }
+    }
+    return err
+}
+
+func postJSON(ctx context.Context, l *slog.Logger, url string, e Event) error {
+    body, err := json.Marshal(e)
+    if err != nil {
+        return err
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+    if s := strings.TrimSpace(string(snippet)); s != "" {
+        l.Info("钩子响应", "status", resp.StatusCode, "body", s)
+    }
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return fmt.Errorf("HTTP %s", resp.Status)
+    }
+    return nil
+}
+
+func defaultInt(v, d int) int {
+    if v <= 0 {
+        return d
+    }
+    return v
+}
@@ -0,0 +1,121 @@
+package logs
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+type Level int
+
+const (
+    LevelDebug Level = iota
+    LevelInfo
+    LevelWarn
+    LevelError
+)
+
+const (
+    SourceFrpc  = "frpc"
+    SourceFrpcx = "frpcx"
+)
+
+func (l Level) String() string {
+    switch l {
+    case LevelDebug:
+        return "D"
+    case LevelWarn:
+        return "W"
+    case LevelError:
+        return "E"
+    default:
+        return "I"
+    }
+}
+
+func (l Level) Label() string {
+    switch l {
+    case LevelDebug:
+        return "调试"
+    case LevelWarn:
+        return "警告"
+    case LevelError:
+        return "错误"
+    default:
+        return "信息"
+    }
+}
+
+func ParseLevel(label string) Level {
+    switch label {
+    case "调试", "D", "debug":
+        return LevelDebug
+    case "警告", "W", "warn":
+        return LevelWarn
+    case "错误", "E", "error":
+        return LevelError
+    default:
+        return LevelInfo
+    }
+}
+
+type Entry struct {
+    Time      time.Time
+    Level     Level
+    Source    string
+    Component string
+    Profile   string
+    Text      string
+}
+
+func (e Entry) String() string {
+    if e.Source == SourceFrpc {
+        return e.Text
+    }
+    tag := e.Source
+    if e.Component != "" {
+        tag += "/" + e.Component
+    }
+    return fmt.Sprintf("%s [%s] [%s] %s", e.Time.Format("2006-01-02 15:04:05.000"), e.Level, tag, e.Text)
+}
+
+func (e Entry) Matches(min Level, query string) bool {
+    if e.Level < min {
+        return false
+    }
+    if query == "" {
+        return true
+    }
+    q := strings.ToLower(query)
+    return strings.Contains(strings.ToLower(e.Text), q) ||
+        strings.Contains(strings.ToLower(e.Profile), q) ||
+        strings.Contains(strings.ToLower(e.Component), q)
+}
+
+func FrpcEntry(profile, line string) Entry {
+    return Entry{
+        Time:    time.Now(),
+        Level:   frpcLevel(line),
+        Source:  SourceFrpc,
+        Profile: profile,
+        Text:    line,
+    }
+}
+
+func frpcLevel(line string) Level {
+    switch {
+    case strings.Contains(line, "[E]"):
+        return LevelError
+    case strings.Contains(line, "[W]"):
+        return LevelWarn
+    case strings.Contains(line, "[D]"), strings.Contains(line, "[T]"):
+        return LevelDebug
+    case strings.Contains(line, "[I]"):
+        return LevelInfo
+    }
+    l := strings.ToLower(line)
+    if strings.Contains(l, "error") || strings.Contains(l, "failed") {
+        return LevelError
+    }
+    return LevelInfo
+}
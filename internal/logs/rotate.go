@@ -0,0 +1,145 @@
+package logs
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+type Options struct {
+    MaxSizeMB  int
+    MaxAgeDays int
+    MaxBackups int
+}
+
+type RotatingFile struct {
+    mu         sync.Mutex
+    path       string
+    maxSize    int64
+    maxAge     time.Duration
+    maxBackups int
+    f          *os.File
+    size       int64
+}
+
+func OpenRotating(path string, opts Options) (*RotatingFile, error) {
+    r := &RotatingFile{
+        path:       path,
+        maxSize:    int64(defaultInt(opts.MaxSizeMB, 5)) * 1024 * 1024,
+        maxAge:     time.Duration(defaultInt(opts.MaxAgeDays, 7)) * 24 * time.Hour,
+        maxBackups: defaultInt(opts.MaxBackups, 5),
+    }
+    if err := r.open(); err != nil {
+        return nil, err
+    }
+    r.cleanup()
+    return r, nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.f == nil {
+        if err := r.open(); err != nil {
+            return 0, err
+        }
+    }
+    if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+        if err := r.rotate(); err != nil {
+            return 0, err
+        }
+    }
+    n, err := r.f.Write(p)
+    r.size += int64(n)
+    return n, err
+}
+
+func (r *RotatingFile) Close() error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.f == nil {
+        return nil
+    }
+    err := r.f.Close()
+    r.f = nil
+    return err
+}
+
+func (r *RotatingFile) open() error {
+    if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+        return err
+    }
+    f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+    if err != nil {
+        return err
+    }
+    info, err := f.Stat()
+    if err != nil {
+        _ = f.Close()
+        return err
+    }
+    r.f = f
+    r.size = info.Size()
+    return nil
+}
+
+func (r *RotatingFile) rotate() error {
+    if r.f != nil {
+        _ = r.f.Close()
+        r.f = nil
+    }
+    ext := filepath.Ext(r.path)
+    base := strings.TrimSuffix(r.path, ext)
+    backup := fmt.Sprintf("%s-%s%s", base, time.Now().Format("20060102-150405.000"), ext)
+    if err := os.Rename(r.path, backup); err != nil && !os.IsNotExist(err) {
+        return err
+    }
+    if err := r.open(); err != nil {
+        return err
+    }
+    r.cleanup()
+    return nil
+}
+
+func (r *RotatingFile) cleanup() {
+    backups := r.backups()
+    cutoff := time.Now().Add(-r.maxAge)
+    keep := 0
+    for _, b := range backups {
+        info, err := os.Stat(b)
+        if err != nil {
+            continue
+        }
+        if keep >= r.maxBackups || info.ModTime().Before(cutoff) {
+            _ = os.Remove(b)
+            continue
+        }
+        keep++
+    }
+}
+
+func (r *RotatingFile) backups() []string {
+    ext := filepath.Ext(r.path)
+    base := strings.TrimSuffix(filepath.Base(r.path), ext)
+    matches, _ := filepath.Glob(filepath.Join(filepath.Dir(r.path), base+"-*"+ext))
+    out := matches[:0]
+    for _, m := range matches {
+        stamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), base+"-"), ext)
+        if _, err := time.Parse("20060102-150405.000", stamp); err == nil {
+            out = append(out, m)
+        }
+    }
+    sort.Sort(sort.Reverse(sort.StringSlice(out)))
+    return out
+}
+
+func defaultInt(v, d int) int {
+    if v <= 0 {
+        return d
+    }
+    return v
+}
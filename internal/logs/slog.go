@@ -0,0 +1,196 @@
+package logs
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "strings"
+    "sync"
+    "time"
+)
+
+var (
+    sharedMu    sync.Mutex
+    shared      *Store
+    sharedLevel = new(slog.LevelVar)
+)
+
+func Setup(dir string, opts Options) *Store {
+    sharedMu.Lock()
+    defer sharedMu.Unlock()
+    if shared != nil {
+        shared.Close()
+    }
+    shared = NewStore(dir, opts)
+    slog.SetDefault(slog.New(&handler{store: shared, level: sharedLevel}))
+    return shared
+}
+
+func Shared() *Store {
+    sharedMu.Lock()
+    defer sharedMu.Unlock()
+    if shared == nil {
+        shared = NewStore("", Options{})
+    }
+    return shared
+}
+
+func SetLevel(name string) {
+    sharedLevel.Set(toSlogLevel(ParseLevel(name)))
+}
+
+func For(component string) *slog.Logger {
+    return slog.New(&componentHandler{component: component})
+}
+
+type componentHandler struct {
+    component string
+    attrs     []slog.Attr
+    groups    []string
+}
+
+func (h *componentHandler) target() slog.Handler {
+    var t slog.Handler = slog.Default().Handler().WithAttrs([]slog.Attr{slog.String("component", h.component)})
+    if len(h.attrs) > 0 {
+        t = t.WithAttrs(h.attrs)
+    }
+    for _, g := range h.groups {
+        t = t.WithGroup(g)
+    }
+    return t
+}
+
+func (h *componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+    return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+    return h.target().Handle(ctx, r)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    out := *h
+    out.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+    return &out
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+    out := *h
+    out.groups = append(append([]string{}, h.groups...), name)
+    return &out
+}
+
+type handler struct {
+    store  *Store
+    level  slog.Leveler
+    attrs  []slog.Attr
+    prefix string
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+    return level >= h.level.Level()
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+    e := Entry{
+        Time:   r.Time,
+        Level:  fromSlogLevel(r.Level),
+        Source: SourceFrpcx,
+    }
+    if e.Time.IsZero() {
+        e.Time = time.Now()
+    }
+
+    var b strings.Builder
+    b.WriteString(r.Message)
+    add := func(a slog.Attr, prefix string) {
+        a.Value = a.Value.Resolve()
+        if a.Equal(slog.Attr{}) {
+            return
+        }
+        switch a.Key {
+        case "component":
+            if prefix == "" {
+                e.Component = a.Value.String()
+                return
+            }
+        case "profile":
+            if prefix == "" {
+                e.Profile = a.Value.String()
+            }
+        }
+        writeAttr(&b, prefix, a)
+    }
+    for _, a := range h.attrs {
+        add(a, "")
+    }
+    r.Attrs(func(a slog.Attr) bool {
+        add(a, h.prefix)
+        return true
+    })
+    e.Text = b.String()
+    h.store.Append(e)
+    return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    out := *h
+    out.attrs = append([]slog.Attr{}, h.attrs...)
+    for _, a := range attrs {
+        if h.prefix != "" {
+            a.Key = h.prefix + a.Key
+        }
+        out.attrs = append(out.attrs, a)
+    }
+    return &out
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+    out := *h
+    out.prefix = h.prefix + name + "."
+    return &out
+}
+
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+    if a.Value.Kind() == slog.KindGroup {
+        for _, ga := range a.Value.Group() {
+            writeAttr(b, prefix+a.Key+".", ga)
+        }
+        return
+    }
+    v := a.Value.String()
+    if strings.ContainsAny(v, " \t\"=") {
+        v = fmt.Sprintf("%q", v)
+    }
+    b.WriteString(" ")
+    b.WriteString(prefix)
+    b.WriteString(a.Key)
+    b.WriteString("=")
+    b.WriteString(v)
+}
+
+func toSlogLevel(l Level) slog.Level {
+    switch l {
+    case LevelDebug:
+        return slog.LevelDebug
+    case LevelWarn:
+        return slog.LevelWarn
+    case LevelError:
+        return slog.LevelError
+    default:
+        return slog.LevelInfo
+    }
+}
+
+func fromSlogLevel(l slog.Level) Level {
+    switch {
+    case l >= slog.LevelError:
+        return LevelError
+    case l >= slog.LevelWarn:
+        return LevelWarn
+    case l >= slog.LevelInfo:
+        return LevelInfo
+    default:
+        return LevelDebug
+    }
+}
@@ -0,0 +1,160 @@
+package logs
+
+import (
+    "archive/zip"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+)
+
+const (
+    maxEntries = 2000
+    appLogName = "frpcx.log"
+)
+
+type Store struct {
+    mu      sync.Mutex
+    dir     string
+    opts    Options
+    files   map[string]*RotatingFile
+    entries []Entry
+    console io.Writer
+}
+
+func NewStore(dir string, opts Options) *Store {
+    return &Store{
+        dir:     dir,
+        opts:    opts,
+        files:   map[string]*RotatingFile{},
+        entries: []Entry{},
+    }
+}
+
+func (s *Store) Dir() string {
+    return s.dir
+}
+
+func (s *Store) SetConsole(w io.Writer) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.console = w
+}
+
+func (s *Store) Append(e Entry) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.entries = append(s.entries, e)
+    if len(s.entries) > maxEntries {
+        s.entries = append([]Entry{}, s.entries[len(s.entries)-maxEntries:]...)
+    }
+    line := e.String() + "\n"
+    if f := s.fileFor(e); f != nil {
+        _, _ = io.WriteString(f, line)
+    }
+    if s.console != nil {
+        _, _ = io.WriteString(s.console, line)
+    }
+}
+
+func (s *Store) Entries() []Entry {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]Entry{}, s.entries...)
+}
+
+func (s *Store) Tail(n int) []string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    start := 0
+    if len(s.entries) > n {
+        start = len(s.entries) - n
+    }
+    out := make([]string, 0, len(s.entries)-start)
+    for _, e := range s.entries[start:] {
+        out = append(out, e.String())
+    }
+    return out
+}
+
+func (s *Store) Close() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for name, f := range s.files {
+        _ = f.Close()
+        delete(s.files, name)
+    }
+}
+
+func (s *Store) Export(w io.Writer) error {
+    zw := zip.NewWriter(w)
+    if err := s.WriteTo(zw, "logs"); err != nil {
+        _ = zw.Close()
+        return err
+    }
+    return zw.Close()
+}
+
+func (s *Store) WriteTo(zw *zip.Writer, prefix string) error {
+    if s.dir == "" {
+        return nil
+    }
+    matches, err := filepath.Glob(filepath.Join(s.dir, "*.log"))
+    if err != nil {
+        return err
+    }
+    for _, m := range matches {
+        if err := addFile(zw, m, prefix+"/"+filepath.Base(m)); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (s *Store) fileFor(e Entry) *RotatingFile {
+    if s.dir == "" {
+        return nil
+    }
+    name := appLogName
+    if e.Source == SourceFrpc {
+        name = "frpc-" + SafeName(e.Profile) + ".log"
+    }
+    if f, ok := s.files[name]; ok {
+        return f
+    }
+    f, err := OpenRotating(filepath.Join(s.dir, name), s.opts)
+    if err != nil {
+        return nil
+    }
+    s.files[name] = f
+    return f
+}
+
+func SafeName(name string) string {
+    name = strings.TrimSpace(strings.ToLower(name))
+    if name == "" {
+        return "default"
+    }
+    return strings.Map(func(r rune) rune {
+        switch r {
+        case ' ', '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+            return '_'
+        }
+        return r
+    }, name)
+}
+
+func addFile(zw *zip.Writer, path, name string) error {
+    f, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+    w, err := zw.Create(name)
+    if err != nil {
+        return err
+    }
+    _, err = io.Copy(w, f)
+    return err
+}
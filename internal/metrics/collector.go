@@ -0,0 +1,220 @@
+package metrics
+
+import (
+    "net/http"
+    "sort"
+    "sync"
+    "time"
+
+    "frpcx/internal/config"
+    "frpcx/internal/frpc"
+    "frpcx/internal/logs"
+)
+
+var log = logs.For("metrics")
+
+const maxSamples = 60
+
+type Sample struct {
+    Time    time.Time
+    InRate  float64
+    OutRate float64
+    Conns   int64
+}
+
+type ProxyMetrics struct {
+    Profile    string
+    Name       string
+    Type       string
+    Status     string
+    TrafficIn  int64
+    TrafficOut int64
+    Conns      int64
+    Updated    time.Time
+    History    []Sample
+}
+
+type Collector struct {
+    mu       sync.Mutex
+    mgr      *frpc.Manager
+    interval time.Duration
+    client   *http.Client
+    proxies  map[string]*ProxyMetrics
+    stop     chan struct{}
+    server   *http.Server
+    listen   string
+}
+
+func NewCollector(mgr *frpc.Manager, cfg config.MetricsConfig) *Collector {
+    return &Collector{
+        mgr:      mgr,
+        interval: time.Duration(defaultInt(cfg.IntervalSec, 5)) * time.Second,
+        client:   &http.Client{Timeout: 5 * time.Second},
+        proxies:  map[string]*ProxyMetrics{},
+    }
+}
+
+func (c *Collector) Start() {
+    c.mu.Lock()
+    if c.stop != nil {
+        c.mu.Unlock()
+        return
+    }
+    c.stop = make(chan struct{})
+    stop := c.stop
+    c.mu.Unlock()
+
+    go func() {
+        ticker := time.NewTicker(c.interval)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-stop:
+                return
+            case <-ticker.C:
+                c.collect()
+            }
+        }
+    }()
+}
+
+func (c *Collector) Stop() {
+    c.mu.Lock()
+    if c.stop != nil {
+        close(c.stop)
+        c.stop = nil
+    }
+    srv := c.server
+    c.server = nil
+    c.listen = ""
+    c.mu.Unlock()
+    if srv != nil {
+        _ = srv.Close()
+    }
+}
+
+func (c *Collector) Apply(cfg config.MetricsConfig) error {
+    c.mu.Lock()
+    srv := c.server
+    listen := c.listen
+    c.mu.Unlock()
+
+    if !cfg.Enabled || cfg.Listen == "" {
+        if srv != nil {
+            c.mu.Lock()
+            c.server = nil
+            c.listen = ""
+            c.mu.Unlock()
+            log.Info("已关闭 metrics 端点", "listen", listen)
+            return srv.Close()
+        }
+        return nil
+    }
+    if srv != nil && listen == cfg.Listen {
+        return nil
+    }
+    if srv != nil {
+        _ = srv.Close()
+    }
+    return c.serve(cfg.Listen)
+}
+
+func (c *Collector) Proxies() []ProxyMetrics {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    out := make([]ProxyMetrics, 0, len(c.proxies))
+    for _, p := range c.proxies {
+        cp := *p
+        cp.History = append([]Sample{}, p.History...)
+        out = append(out, cp)
+    }
+    sort.Slice(out, func(i, j int) bool {
+        if out[i].Profile != out[j].Profile {
+            return out[i].Profile < out[j].Profile
+        }
+        return out[i].Name < out[j].Name
+    })
+    return out
+}
+
+func (c *Collector) collect() {
+    p, cfgPath, ok := c.mgr.Active()
+    if !ok {
+        return
+    }
+    info, err := frpc.LoadClientInfo(cfgPath)
+    if err != nil {
+        log.Debug("读取 frpc 配置失败", "profile", p.Name, "err", err)
+        return
+    }
+
+    now := time.Now()
+    stats := map[string]proxyStat{}
+    if p.Dashboard.URL != "" {
+        if s, err := c.fetchDashboard(p.Dashboard, info); err != nil {
+            log.Debug("获取 frps 面板数据失败", "profile", p.Name, "err", err)
+        } else {
+            stats = s
+        }
+    }
+    adminStatus := map[string]string{}
+    if info.WebServerPort > 0 {
+        if s, err := c.fetchAdminStatus(info); err != nil {
+            log.Debug("获取 frpc 管理接口状态失败", "profile", p.Name, "err", err)
+        } else {
+            adminStatus = s
+        }
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    for _, px := range info.Proxies {
+        key := p.Name + "/" + px.Name
+        pm, ok := c.proxies[key]
+        if !ok {
+            pm = &ProxyMetrics{Profile: p.Name, Name: px.Name}
+            c.proxies[key] = pm
+        }
+        pm.Type = px.Type
+        if st, ok := adminStatus[px.Name]; ok {
+            pm.Status = st
+        }
+        st, ok := stats[px.Name]
+        if !ok {
+            continue
+        }
+        if st.Status != "" && pm.Status == "" {
+            pm.Status = st.Status
+        }
+        sample := Sample{Time: now, Conns: st.Conns}
+        if !pm.Updated.IsZero() {
+            secs := now.Sub(pm.Updated).Seconds()
+            if secs > 0 {
+                sample.InRate = rate(pm.TrafficIn, st.TrafficIn, secs)
+                sample.OutRate = rate(pm.TrafficOut, st.TrafficOut, secs)
+            }
+        }
+        pm.TrafficIn = st.TrafficIn
+        pm.TrafficOut = st.TrafficOut
+        pm.Conns = st.Conns
+        pm.Updated = now
+        pm.History = append(pm.History, sample)
+        if len(pm.History) > maxSamples {
+            pm.History = pm.History[len(pm.History)-maxSamples:]
+        }
+    }
+}
+
+func rate(prev, cur int64, secs float64) float64 {
+    if cur < prev {
+        return float64(cur) / secs
+    }
+    return float64(cur-prev) / secs
+}
+
+func defaultInt(v, d int) int {
+    if v <= 0 {
+        return d
+    }
+    return v
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "sort"
+    "strings"
+    "time"
+
+    "frpcx/internal/frpc"
+)
+
+func (c *Collector) serve(listen string) error {
+    ln, err := net.Listen("tcp", listen)
+    if err != nil {
+        return fmt.Errorf("metrics 端点监听失败: %w", err)
+    }
+    mux := http.NewServeMux()
+    mux.Handle("/metrics", c.Handler())
+    srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+    c.mu.Lock()
+    c.server = srv
+    c.listen = listen
+    c.mu.Unlock()
+
+    go func() {
+        if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+            log.Error("metrics 端点异常退出", "listen", listen, "err", err)
+        }
+    }()
+    log.Info("已开启 metrics 端点", "listen", listen)
+    return nil
+}
+
+func (c *Collector) Handler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+        w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+        c.WriteText(w)
+    })
+}
+
+func (c *Collector) WriteText(w io.Writer) {
+    now := time.Now()
+    stats := c.mgr.Stats()
+    sort.Slice(stats, func(i, j int) bool { return stats[i].Profile < stats[j].Profile })
+
+    header(w, "frpcx_tunnel_up", "gauge", "Whether the tunnel for the profile is running.")
+    for _, s := range stats {
+        fmt.Fprintf(w, "frpcx_tunnel_up{profile=%s} %d\n", labelValue(s.Profile), boolInt(s.Running))
+    }
+    header(w, "frpcx_tunnel_uptime_seconds", "gauge", "Seconds since the tunnel became ready.")
+    for _, s := range stats {
+        uptime := 0.0
+        if s.Running {
+            uptime = now.Sub(s.RunningSince).Seconds()
+        }
+        fmt.Fprintf(w, "frpcx_tunnel_uptime_seconds{profile=%s} %.0f\n", labelValue(s.Profile), uptime)
+    }
+    header(w, "frpcx_tunnel_restarts_total", "counter", "Number of times frpc was restarted for the profile.")
+    for _, s := range stats {
+        fmt.Fprintf(w, "frpcx_tunnel_restarts_total{profile=%s} %d\n", labelValue(s.Profile), s.Restarts)
+    }
+    header(w, "frpcx_tunnel_reconnects_total", "counter", "Number of reconnects to frps reported by frpc.")
+    for _, s := range stats {
+        fmt.Fprintf(w, "frpcx_tunnel_reconnects_total{profile=%s} %d\n", labelValue(s.Profile), s.Reconnects)
+    }
+    header(w, "frpcx_circuit_open", "gauge", "Whether the profile is skipped by the circuit breaker.")
+    for _, ci := range c.mgr.Circuits() {
+        fmt.Fprintf(w, "frpcx_circuit_open{profile=%s} %d\n", labelValue(ci.Profile), boolInt(ci.State == frpc.CircuitOpen))
+    }
+
+    proxies := c.Proxies()
+    header(w, "frpcx_proxy_traffic_in_bytes", "gauge", "Bytes received by the proxy today, as reported by frps.")
+    for _, p := range proxies {
+        fmt.Fprintf(w, "frpcx_proxy_traffic_in_bytes{%s} %d\n", proxyLabels(p), p.TrafficIn)
+    }
+    header(w, "frpcx_proxy_traffic_out_bytes", "gauge", "Bytes sent by the proxy today, as reported by frps.")
+    for _, p := range proxies {
+        fmt.Fprintf(w, "frpcx_proxy_traffic_out_bytes{%s} %d\n", proxyLabels(p), p.TrafficOut)
+    }
+    header(w, "frpcx_proxy_connections", "gauge", "Current connections of the proxy.")
+    for _, p := range proxies {
+        fmt.Fprintf(w, "frpcx_proxy_connections{%s} %d\n", proxyLabels(p), p.Conns)
+    }
+    header(w, "frpcx_proxy_up", "gauge", "Whether the proxy is reported as running.")
+    for _, p := range proxies {
+        fmt.Fprintf(w, "frpcx_proxy_up{%s} %d\n", proxyLabels(p), boolInt(p.Status == "running" || p.Status == "online"))
+    }
+}
+
+func header(w io.Writer, name, typ, help string) {
+    fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func proxyLabels(p ProxyMetrics) string {
+    return fmt.Sprintf("profile=%s,proxy=%s,type=%s", labelValue(p.Profile), labelValue(p.Name), labelValue(p.Type))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelValue(s string) string {
+    return `"` + labelEscaper.Replace(strings.ToValidUTF8(s, "")) + `"`
+}
+
+func boolInt(b bool) int {
+    if b {
+        return 1
+    }
+    return 0
+}
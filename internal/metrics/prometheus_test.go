@@ -0,0 +1,132 @@
+package metrics
+
+import (
+    "encoding/json"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+
+    "frpcx/internal/config"
+    "frpcx/internal/frpc"
+)
+
+func newTestCollector() *Collector {
+    return NewCollector(frpc.NewManager(&config.AppConfig{}), config.MetricsConfig{})
+}
+
+func TestWriteText(t *testing.T) {
+    c := newTestCollector()
+    c.proxies["home/ssh"] = &ProxyMetrics{Profile: "home", Name: "ssh", Type: "tcp", Status: "running", TrafficIn: 1024, TrafficOut: 2048, Conns: 3}
+    c.proxies["home/we\"b"] = &ProxyMetrics{Profile: "home", Name: "we\"b", Type: "http", Status: "offline"}
+
+    srv := httptest.NewServer(c.Handler())
+    defer srv.Close()
+    resp, err := http.Get(srv.URL)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer resp.Body.Close()
+    if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+        t.Fatalf("Content-Type = %q", ct)
+    }
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        t.Fatal(err)
+    }
+    out := string(body)
+
+    for _, want := range []string{
+        "# HELP frpcx_tunnel_up Whether the tunnel for the profile is running.\n# TYPE frpcx_tunnel_up gauge\n",
+        "# TYPE frpcx_tunnel_restarts_total counter\n",
+        `frpcx_proxy_traffic_in_bytes{profile="home",proxy="ssh",type="tcp"} 1024` + "\n",
+        `frpcx_proxy_traffic_out_bytes{profile="home",proxy="ssh",type="tcp"} 2048` + "\n",
+        `frpcx_proxy_connections{profile="home",proxy="ssh",type="tcp"} 3` + "\n",
+        `frpcx_proxy_up{profile="home",proxy="ssh",type="tcp"} 1` + "\n",
+        `frpcx_proxy_up{profile="home",proxy="we\"b",type="http"} 0` + "\n",
+    } {
+        if !strings.Contains(out, want) {
+            t.Errorf("missing %q in:\n%s", want, out)
+        }
+    }
+    if strings.Index(out, `proxy="ssh"`) > strings.Index(out, `proxy="we\"b"`) {
+        t.Error("proxies are not sorted by name")
+    }
+}
+
+func TestLabelValue(t *testing.T) {
+    cases := map[string]string{
+        "home":       `"home"`,
+        `a"b`:        `"a\"b"`,
+        `c:\frp`:     `"c:\\frp"`,
+        "line\nnext": `"line\nnext"`,
+        "家\tlab":     "\"家\tlab\"",
+        "bad\xffutf": `"badutf"`,
+    }
+    for in, want := range cases {
+        if got := labelValue(in); got != want {
+            t.Errorf("labelValue(%q) = %s, want %s", in, got, want)
+        }
+    }
+}
+
+func TestFetchDashboard(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if u, p, ok := r.BasicAuth(); !ok || u != "admin" || p != "secret" {
+            w.WriteHeader(http.StatusUnauthorized)
+            return
+        }
+        if r.URL.Path != "/api/proxy/tcp" {
+            http.NotFound(w, r)
+            return
+        }
+        _ = json.NewEncoder(w).Encode(map[string]any{"proxies": []dashboardProxy{
+            {Name: "alice.ssh", Status: "online", CurConns: 2, TodayTrafficIn: 10, TodayTrafficOut: 20},
+            {Name: "bob.ssh", Status: "online", CurConns: 9},
+        }})
+    }))
+    defer srv.Close()
+
+    c := newTestCollector()
+    info := &frpc.ClientInfo{User: "alice", Proxies: []frpc.ProxyInfo{{Name: "ssh", Type: "tcp"}}}
+    stats, err := c.fetchDashboard(config.DashboardConfig{URL: srv.URL + "/", Username: "admin", Password: "secret"}, info)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := proxyStat{Status: "online", TrafficIn: 10, TrafficOut: 20, Conns: 2}
+    if len(stats) != 1 || stats["ssh"] != want {
+        t.Fatalf("stats = %+v", stats)
+    }
+
+    if _, err := c.fetchDashboard(config.DashboardConfig{URL: srv.URL, Username: "admin", Password: "wrong"}, info); err == nil {
+        t.Fatal("unauthorized dashboard accepted")
+    }
+}
+
+func TestFetchAdminStatus(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path != "/api/status" {
+            http.NotFound(w, r)
+            return
+        }
+        _ = json.NewEncoder(w).Encode(map[string][]adminProxy{
+            "tcp":  {{Name: "ssh", Status: "running"}},
+            "http": {{Name: "web", Status: "start error"}},
+        })
+    }))
+    defer srv.Close()
+    host, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+    p, _ := strconv.Atoi(port)
+
+    c := newTestCollector()
+    got, err := c.fetchAdminStatus(&frpc.ClientInfo{WebServerAddr: host, WebServerPort: p})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got["ssh"] != "running" || got["web"] != "start error" {
+        t.Fatalf("status = %+v", got)
+    }
+}
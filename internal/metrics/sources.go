@@ -0,0 +1,110 @@
+package metrics
+
+import (
+    "encoding/json"
+    "fmt"
+    "net"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "frpcx/internal/config"
+    "frpcx/internal/frpc"
+)
+
+type proxyStat struct {
+    Status     string
+    TrafficIn  int64
+    TrafficOut int64
+    Conns      int64
+}
+
+type dashboardProxy struct {
+    Name            string `json:"name"`
+    Status          string `json:"status"`
+    CurConns        int64  `json:"curConns"`
+    TodayTrafficIn  int64  `json:"todayTrafficIn"`
+    TodayTrafficOut int64  `json:"todayTrafficOut"`
+}
+
+type adminProxy struct {
+    Name   string `json:"name"`
+    Status string `json:"status"`
+}
+
+func (c *Collector) fetchDashboard(d config.DashboardConfig, info *frpc.ClientInfo) (map[string]proxyStat, error) {
+    names := map[string]string{}
+    types := map[string]bool{}
+    for _, px := range info.Proxies {
+        remote := px.Name
+        if info.User != "" {
+            remote = info.User + "." + px.Name
+        }
+        names[remote] = px.Name
+        if px.Type != "" {
+            types[px.Type] = true
+        }
+    }
+
+    out := map[string]proxyStat{}
+    for t := range types {
+        var body struct {
+            Proxies []dashboardProxy `json:"proxies"`
+        }
+        url := strings.TrimRight(d.URL, "/") + "/api/proxy/" + t
+        if err := c.getJSON(url, d.Username, d.Password, &body); err != nil {
+            return nil, err
+        }
+        for _, px := range body.Proxies {
+            local, ok := names[px.Name]
+            if !ok {
+                continue
+            }
+            out[local] = proxyStat{
+                Status:     px.Status,
+                TrafficIn:  px.TodayTrafficIn,
+                TrafficOut: px.TodayTrafficOut,
+                Conns:      px.CurConns,
+            }
+        }
+    }
+    return out, nil
+}
+
+func (c *Collector) fetchAdminStatus(info *frpc.ClientInfo) (map[string]string, error) {
+    host := info.WebServerAddr
+    if host == "" || host == "0.0.0.0" {
+        host = "127.0.0.1"
+    }
+    url := "http://" + net.JoinHostPort(host, strconv.Itoa(info.WebServerPort)) + "/api/status"
+    var body map[string][]adminProxy
+    if err := c.getJSON(url, info.WebServerUser, info.WebServerPassword, &body); err != nil {
+        return nil, err
+    }
+    out := map[string]string{}
+    for _, list := range body {
+        for _, px := range list {
+            out[px.Name] = px.Status
+        }
+    }
+    return out, nil
+}
+
+func (c *Collector) getJSON(url, user, password string, v any) error {
+    req, err := http.NewRequest(http.MethodGet, url, nil)
+    if err != nil {
+        return err
+    }
+    if user != "" || password != "" {
+        req.SetBasicAuth(user, password)
+    }
+    resp, err := c.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("%s: %s", url, resp.Status)
+    }
+    return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,169 @@
+package netwatch
+
+import (
+    "context"
+    "fmt"
+    "net"
+    "slices"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "frpcx/internal/logs"
+)
+
+var log = logs.For("netwatch")
+
+const (
+    KindNetwork = "network"
+    KindResume  = "resume"
+)
+
+const (
+    debounceDelay = 2 * time.Second
+    clockInterval = 5 * time.Second
+    resumeGap     = 30 * time.Second
+)
+
+type Event struct {
+    Kind   string
+    Detail string
+    Time   time.Time
+}
+
+type Watcher struct {
+    cancel  context.CancelFunc
+    done    chan struct{}
+    changed chan string
+    resumed chan string
+    poll    time.Duration
+    handler func(Event)
+}
+
+func Start(poll time.Duration, handler func(Event)) *Watcher {
+    if poll <= 0 {
+        poll = 15 * time.Second
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    w := &Watcher{
+        cancel:  cancel,
+        done:    make(chan struct{}),
+        changed: make(chan string, 1),
+        resumed: make(chan string, 1),
+        poll:    poll,
+        handler: handler,
+    }
+    go w.run(ctx)
+    return w
+}
+
+func (w *Watcher) Stop() {
+    w.cancel()
+    <-w.done
+}
+
+func (w *Watcher) notifyChange(detail string) {
+    select {
+    case w.changed <- detail:
+    default:
+    }
+}
+
+func (w *Watcher) notifyResume(detail string) {
+    select {
+    case w.resumed <- detail:
+    default:
+    }
+}
+
+func (w *Watcher) emit(kind, detail string) {
+    log.Info("检测到网络变化", "kind", kind, "detail", detail)
+    w.handler(Event{Kind: kind, Detail: detail, Time: time.Now()})
+}
+
+func (w *Watcher) run(ctx context.Context) {
+    defer close(w.done)
+    var wg sync.WaitGroup
+    defer wg.Wait()
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        watchPlatform(ctx, w)
+    }()
+
+    last := addrFingerprint()
+    poll := time.NewTicker(w.poll)
+    defer poll.Stop()
+    clock := time.NewTicker(clockInterval)
+    defer clock.Stop()
+    lastTick := time.Now().Round(0)
+    var lastResume time.Time
+    var debounce <-chan time.Time
+    pending := ""
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case detail := <-w.changed:
+            pending = detail
+            debounce = time.After(debounceDelay)
+        case <-debounce:
+            debounce = nil
+            if fp := addrFingerprint(); fp != last {
+                last = fp
+                w.emit(KindNetwork, pending)
+            }
+        case <-poll.C:
+            if fp := addrFingerprint(); fp != last {
+                last = fp
+                w.emit(KindNetwork, "地址变化")
+            }
+        case detail := <-w.resumed:
+            lastResume = time.Now()
+            last = addrFingerprint()
+            w.emit(KindResume, detail)
+        case now := <-clock.C:
+            wall := now.Round(0)
+            gap := wall.Sub(lastTick)
+            lastTick = wall
+            if gap > clockInterval+resumeGap && time.Since(lastResume) > resumeGap {
+                last = addrFingerprint()
+                w.emit(KindResume, fmt.Sprintf("时钟跳变 %s", gap.Round(time.Second)))
+            }
+        }
+    }
+}
+
+func addrFingerprint() string {
+    ifaces, err := net.Interfaces()
+    if err != nil {
+        return ""
+    }
+    var parts []string
+    for _, iface := range ifaces {
+        if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+            continue
+        }
+        addrs, err := iface.Addrs()
+        if err != nil {
+            continue
+        }
+        for _, a := range addrs {
+            ipnet, ok := a.(*net.IPNet)
+            if !ok || ipnet.IP.IsLinkLocalUnicast() || ipnet.IP.IsLoopback() {
+                continue
+            }
+            addr := ipnet.IP.String()
+            if ipnet.IP.To4() == nil {
+                ones, _ := ipnet.Mask.Size()
+                addr = ipnet.IP.Mask(ipnet.Mask).String() + "/" + strconv.Itoa(ones)
+            }
+            parts = append(parts, iface.Name+"="+addr)
+        }
+    }
+    sort.Strings(parts)
+    return strings.Join(slices.Compact(parts), ",")
+}
@@ -0,0 +1,112 @@
+package netwatch
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "syscall"
+
+    "github.com/godbus/dbus/v5"
+    "golang.org/x/sys/unix"
+)
+
+func watchPlatform(ctx context.Context, w *Watcher) {
+    var wg sync.WaitGroup
+    wg.Add(2)
+    go func() {
+        defer wg.Done()
+        watchNetlink(ctx, w)
+    }()
+    go func() {
+        defer wg.Done()
+        watchSleep(ctx, w)
+    }()
+    wg.Wait()
+}
+
+func watchNetlink(ctx context.Context, w *Watcher) {
+    fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+    if err != nil {
+        log.Warn("无法监听 netlink，改用定时检查", "err", err)
+        return
+    }
+    defer unix.Close(fd)
+    sa := &unix.SockaddrNetlink{
+        Family: unix.AF_NETLINK,
+        Groups: unix.RTMGRP_LINK | unix.RTMGRP_IPV4_IFADDR | unix.RTMGRP_IPV6_IFADDR,
+    }
+    if err := unix.Bind(fd, sa); err != nil {
+        log.Warn("无法监听 netlink，改用定时检查", "err", err)
+        return
+    }
+    tv := unix.Timeval{Sec: 1}
+    _ = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv)
+
+    buf := make([]byte, 1<<16)
+    for ctx.Err() == nil {
+        n, _, err := unix.Recvfrom(fd, buf, 0)
+        if err != nil {
+            switch {
+            case errors.Is(err, unix.EAGAIN), errors.Is(err, unix.EINTR):
+                continue
+            case errors.Is(err, unix.ENOBUFS):
+                w.notifyChange("netlink 消息溢出")
+                continue
+            }
+            log.Warn("读取 netlink 失败，改用定时检查", "err", err)
+            return
+        }
+        msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+        if err != nil {
+            continue
+        }
+        for _, msg := range msgs {
+            switch msg.Header.Type {
+            case unix.RTM_NEWADDR, unix.RTM_DELADDR:
+                w.notifyChange("地址变化")
+            case unix.RTM_NEWLINK, unix.RTM_DELLINK:
+                w.notifyChange("网卡变化")
+            }
+        }
+    }
+}
+
+func watchSleep(ctx context.Context, w *Watcher) {
+    conn, err := dbus.ConnectSystemBus()
+    if err != nil {
+        log.Debug("无法连接系统 D-Bus，改用时钟检测唤醒", "err", err)
+        return
+    }
+    defer conn.Close()
+    err = conn.AddMatchSignal(
+        dbus.WithMatchObjectPath("/org/freedesktop/login1"),
+        dbus.WithMatchInterface("org.freedesktop.login1.Manager"),
+        dbus.WithMatchMember("PrepareForSleep"),
+    )
+    if err != nil {
+        log.Debug("订阅 logind 休眠信号失败，改用时钟检测唤醒", "err", err)
+        return
+    }
+    ch := make(chan *dbus.Signal, 4)
+    conn.Signal(ch)
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case sig, ok := <-ch:
+            if !ok {
+                return
+            }
+            if sig.Name != "org.freedesktop.login1.Manager.PrepareForSleep" || len(sig.Body) == 0 {
+                continue
+            }
+            if sleeping, ok := sig.Body[0].(bool); ok {
+                if sleeping {
+                    log.Info("系统即将休眠")
+                } else {
+                    w.notifyResume("系统唤醒")
+                }
+            }
+        }
+    }
+}
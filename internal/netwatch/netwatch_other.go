@@ -0,0 +1,9 @@
+//go:build !linux
+
+package netwatch
+
+import "context"
+
+func watchPlatform(ctx context.Context, w *Watcher) {
+    <-ctx.Done()
+}
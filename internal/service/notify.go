@@ -0,0 +1,36 @@
+package service
+
+import (
+    "net"
+    "os"
+    "strconv"
+    "time"
+)
+
+func Notify(state string) error {
+    addr := os.Getenv("NOTIFY_SOCKET")
+    if addr == "" {
+        return nil
+    }
+    if addr[0] == '@' {
+        addr = "\x00" + addr[1:]
+    }
+    conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    _, err = conn.Write([]byte(state))
+    return err
+}
+
+func WatchdogInterval() time.Duration {
+    usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+    if err != nil || usec <= 0 {
+        return 0
+    }
+    if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+        return 0
+    }
+    return time.Duration(usec) * time.Microsecond
+}
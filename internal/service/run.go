@@ -0,0 +1,143 @@
+package service
+
+import (
+    "fmt"
+    "os"
+    "os/signal"
+    "sync"
+    "syscall"
+    "time"
+
+    "frpcx/internal/config"
+    "frpcx/internal/frpc"
+    "frpcx/internal/metrics"
+    "frpcx/internal/netwatch"
+)
+
+func Run(cfg *config.AppConfig, profiles []string) error {
+    if len(profiles) > 0 {
+        if err := selectProfiles(cfg, profiles); err != nil {
+            return err
+        }
+    }
+
+    for _, rec := range frpc.FindOrphans() {
+        log.Warn("结束残留的 frpc 进程", "profile", rec.Profile, "pid", rec.PID)
+        if err := frpc.TerminateOrphan(rec, 5*time.Second); err != nil {
+            log.Error("结束残留进程失败", "pid", rec.PID, "err", err)
+        }
+    }
+
+    mgr := frpc.NewManager(cfg)
+    collector := metrics.NewCollector(mgr, cfg.Metrics)
+    collector.Start()
+    if err := collector.Apply(cfg.Metrics); err != nil {
+        log.Error("开启 metrics 端点失败", "listen", cfg.Metrics.Listen, "err", err)
+    }
+    defer collector.Stop()
+
+    if cfg.NetWatch.Enabled {
+        w := netwatch.Start(time.Duration(cfg.NetWatch.PollSec)*time.Second, func(e netwatch.Event) {
+            go mgr.Recheck(e.Detail)
+        })
+        defer w.Stop()
+    }
+
+    var ready sync.Once
+    mgr.Subscribe(func(e frpc.Event) {
+        switch e.Type {
+        case frpc.EventReady:
+            ready.Do(func() { _ = Notify("READY=1") })
+            _ = Notify("STATUS=运行中: " + e.Profile)
+        case frpc.EventFailover:
+            _ = Notify(fmt.Sprintf("STATUS=已从 %s 切换到 %s", e.From, e.Profile))
+        case frpc.EventFailback:
+            _ = Notify(fmt.Sprintf("STATUS=已从 %s 切回 %s", e.From, e.Profile))
+        case frpc.EventFailed:
+            _ = Notify("STATUS=失败: " + e.Message)
+        }
+    })
+
+    sigCh := make(chan os.Signal, 1)
+    signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+    defer signal.Stop(sigCh)
+
+    log.Info("以无界面模式启动", "profiles", len(enabledNames(cfg)))
+    _ = Notify("STATUS=正在连接")
+    go mgr.StartAuto()
+
+    interval := time.Second * 5
+    watchdog := WatchdogInterval()
+    if watchdog > 0 && watchdog/2 < interval {
+        interval = watchdog / 2
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    var failedSince time.Time
+    backoff := 10 * time.Second
+    for {
+        select {
+        case sig := <-sigCh:
+            log.Info("收到退出信号", "signal", sig.String())
+            _ = Notify("STOPPING=1")
+            mgr.Stop()
+            return nil
+        case <-ticker.C:
+            if watchdog > 0 {
+                if err := mgr.Ping(interval); err != nil {
+                    log.Error("跳过看门狗通知", "err", err)
+                } else {
+                    _ = Notify("WATCHDOG=1")
+                }
+            }
+            if mgr.Status().Status != "error" {
+                failedSince = time.Time{}
+                backoff = 10 * time.Second
+                continue
+            }
+            if failedSince.IsZero() {
+                failedSince = time.Now()
+            }
+            if time.Since(failedSince) >= backoff {
+                log.Info("重新尝试启动", "after", backoff)
+                _ = Notify("STATUS=重新尝试启动")
+                failedSince = time.Time{}
+                if backoff < 5*time.Minute {
+                    backoff *= 2
+                }
+                go mgr.StartAuto()
+            }
+        }
+    }
+}
+
+func selectProfiles(cfg *config.AppConfig, names []string) error {
+    want := map[string]bool{}
+    for _, n := range names {
+        want[n] = true
+    }
+    found := 0
+    for i := range cfg.Profiles {
+        cfg.Profiles[i].Enabled = want[cfg.Profiles[i].Name]
+        if cfg.Profiles[i].Enabled {
+            found++
+        }
+    }
+    if found != len(want) {
+        return fmt.Errorf("部分配置不存在: %v", names)
+    }
+    cfg.ActiveProfile = names[0]
+    cfg.AutoSwitch = len(names) > 1
+    return nil
+}
+
+func enabledNames(cfg *config.AppConfig) []string {
+    var out []string
+    for _, p := range cfg.Profiles {
+        if p.Enabled {
+            out = append(out, p.Name)
+        }
+    }
+    return out
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "os/user"
+    "path/filepath"
+    "runtime"
+    "strings"
+
+    "frpcx/internal/logs"
+)
+
+var log = logs.For("service")
+
+const (
+    ScopeUser   = "user"
+    ScopeSystem = "system"
+)
+
+type Options struct {
+    Name     string
+    Scope    string
+    Profiles []string
+    RunAs    string
+    Exec     string
+}
+
+func (o *Options) normalize() error {
+    if runtime.GOOS != "linux" {
+        return errors.New("系统服务仅支持 Linux systemd")
+    }
+    if o.Name == "" {
+        o.Name = "frpcx"
+    }
+    if o.Scope == "" {
+        o.Scope = ScopeUser
+    }
+    if o.Scope != ScopeUser && o.Scope != ScopeSystem {
+        return fmt.Errorf("未知的服务范围: %s", o.Scope)
+    }
+    if o.Exec == "" {
+        exe, err := os.Executable()
+        if err != nil {
+            return err
+        }
+        if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+            exe = resolved
+        }
+        o.Exec = exe
+    }
+    if o.Scope == ScopeSystem && o.RunAs == "" {
+        o.RunAs = os.Getenv("SUDO_USER")
+        if o.RunAs == "" {
+            if u, err := user.Current(); err == nil {
+                o.RunAs = u.Username
+            }
+        }
+    }
+    return nil
+}
+
+func RenderUnit(o Options) (string, error) {
+    if err := o.normalize(); err != nil {
+        return "", err
+    }
+    args := []string{quoteArg(o.Exec), "run"}
+    if len(o.Profiles) > 0 {
+        args = append(args, "--profiles", quoteArg(strings.Join(o.Profiles, ",")))
+    }
+
+    var b strings.Builder
+    b.WriteString("[Unit]\n")
+    fmt.Fprintf(&b, "Description=frpcx tunnel (%s)\n", o.Name)
+    b.WriteString("After=network-online.target\n")
+    b.WriteString("Wants=network-online.target\n\n")
+
+    b.WriteString("[Service]\n")
+    b.WriteString("Type=notify\n")
+    b.WriteString("NotifyAccess=main\n")
+    fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(args, " "))
+    if o.Scope == ScopeSystem && o.RunAs != "" {
+        fmt.Fprintf(&b, "User=%s\n", o.RunAs)
+    }
+    b.WriteString("Restart=on-failure\n")
+    b.WriteString("RestartSec=5\n")
+    b.WriteString("TimeoutStartSec=infinity\n")
+    b.WriteString("WatchdogSec=60\n")
+    b.WriteString("TimeoutStopSec=30\n")
+    b.WriteString("StandardOutput=journal\n")
+    b.WriteString("StandardError=journal\n\n")
+
+    b.WriteString("[Install]\n")
+    if o.Scope == ScopeSystem {
+        b.WriteString("WantedBy=multi-user.target\n")
+    } else {
+        b.WriteString("WantedBy=default.target\n")
+    }
+    return b.String(), nil
+}
+
+func UnitPath(o Options) (string, error) {
+    if err := o.normalize(); err != nil {
+        return "", err
+    }
+    if o.Scope == ScopeSystem {
+        return filepath.Join("/etc/systemd/system", o.Name+".service"), nil
+    }
+    dir, err := os.UserConfigDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(dir, "systemd", "user", o.Name+".service"), nil
+}
+
+func Install(o Options) error {
+    if err := o.normalize(); err != nil {
+        return err
+    }
+    unit, err := RenderUnit(o)
+    if err != nil {
+        return err
+    }
+    path, err := UnitPath(o)
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return err
+    }
+    if err := os.WriteFile(path, []byte(unit), 0o644); err != nil {
+        return fmt.Errorf("写入服务文件失败: %w", err)
+    }
+    log.Info("已写入服务文件", "path", path, "scope", o.Scope)
+
+    if err := systemctl(o.Scope, "daemon-reload"); err != nil {
+        return err
+    }
+    if err := systemctl(o.Scope, "enable", "--now", o.Name+".service"); err != nil {
+        return err
+    }
+    if o.Scope == ScopeUser {
+        if u, err := user.Current(); err == nil {
+            if err := exec.Command("loginctl", "enable-linger", u.Username).Run(); err != nil {
+                log.Warn("开启 linger 失败，未登录时用户服务不会启动", "user", u.Username, "err", err)
+            }
+        }
+    }
+    return nil
+}
+
+func Uninstall(o Options) error {
+    if err := o.normalize(); err != nil {
+        return err
+    }
+    path, err := UnitPath(o)
+    if err != nil {
+        return err
+    }
+    if err := systemctl(o.Scope, "disable", "--now", o.Name+".service"); err != nil {
+        log.Warn("停用服务失败", "name", o.Name, "err", err)
+    }
+    if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+        return fmt.Errorf("删除服务文件失败: %w", err)
+    }
+    log.Info("已删除服务文件", "path", path)
+    return systemctl(o.Scope, "daemon-reload")
+}
+
+func Status(o Options) (string, error) {
+    if err := o.normalize(); err != nil {
+        return "", err
+    }
+    args := []string{"status", "--no-pager", o.Name + ".service"}
+    if o.Scope == ScopeUser {
+        args = append([]string{"--user"}, args...)
+    }
+    out, err := exec.Command("systemctl", args...).CombinedOutput()
+    if err != nil {
+        var exitErr *exec.ExitError
+        if errors.As(err, &exitErr) && len(out) > 0 {
+            return string(out), nil
+        }
+        return string(out), err
+    }
+    return string(out), nil
+}
+
+func systemctl(scope string, args ...string) error {
+    if scope == ScopeUser {
+        args = append([]string{"--user"}, args...)
+    }
+    out, err := exec.Command("systemctl", args...).CombinedOutput()
+    if err != nil {
+        msg := strings.TrimSpace(string(out))
+        if msg == "" {
+            msg = err.Error()
+        }
+        return fmt.Errorf("systemctl %s: %s", strings.Join(args, " "), msg)
+    }
+    return nil
+}
+
+func quoteArg(s string) string {
+    s = strings.ReplaceAll(s, "%", "%%")
+    if !strings.ContainsAny(s, " \t\"'\\") {
+        return s
+    }
+    return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(s) + "\""
+}
@@ -0,0 +1,184 @@
+package tmpl
+
+import (
+    "fmt"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+var (
+    placeholderRe = regexp.MustCompile(`\{\{([A-Za-z_][A-Za-z0-9_]*)(?::([^{}]*))?\}\}`)
+    nameRe        = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+const (
+    TypeString = "string"
+    TypeInt    = "int"
+    TypePort   = "port"
+)
+
+type Var struct {
+    Name       string
+    Type       string
+    Default    string
+    HasDefault bool
+    Pattern    *regexp.Regexp
+    Desc       string
+}
+
+type Template struct {
+    Source string
+    Vars   []Var
+}
+
+func ParseFile(path string) (*Template, error) {
+    b, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    return Parse(string(b))
+}
+
+func Parse(src string) (*Template, error) {
+    t := &Template{Source: src}
+    index := map[string]int{}
+    get := func(name string) *Var {
+        i, ok := index[name]
+        if !ok {
+            i = len(t.Vars)
+            index[name] = i
+            t.Vars = append(t.Vars, Var{Name: name, Type: TypeString})
+        }
+        return &t.Vars[i]
+    }
+
+    for n, line := range strings.Split(src, "\n") {
+        trimmed := strings.TrimSpace(line)
+        if rest, ok := strings.CutPrefix(trimmed, "#"); ok {
+            rest = strings.TrimSpace(rest)
+            if decl, ok := strings.CutPrefix(rest, "@var"); ok {
+                if err := parseDecl(get, strings.TrimSpace(decl)); err != nil {
+                    return nil, fmt.Errorf("第 %d 行: %w", n+1, err)
+                }
+            }
+            continue
+        }
+        for _, m := range placeholderRe.FindAllStringSubmatch(line, -1) {
+            v := get(m[1])
+            if strings.Contains(m[0], ":") {
+                if v.HasDefault && v.Default != m[2] {
+                    return nil, fmt.Errorf("第 %d 行: 变量 %s 的默认值前后不一致", n+1, v.Name)
+                }
+                v.Default, v.HasDefault = m[2], true
+            }
+        }
+    }
+    for _, v := range t.Vars {
+        if v.HasDefault && v.Default != "" {
+            if err := v.Validate(v.Default); err != nil {
+                return nil, fmt.Errorf("默认值无效: %w", err)
+            }
+        }
+    }
+    return t, nil
+}
+
+func parseDecl(get func(string) *Var, decl string) error {
+    name, rest, _ := strings.Cut(decl, " ")
+    if !nameRe.MatchString(name) {
+        return fmt.Errorf("变量名无效: %q", name)
+    }
+    v := get(name)
+    rest = strings.TrimSpace(rest)
+    for rest != "" {
+        if d, ok := strings.CutPrefix(rest, "desc="); ok {
+            v.Desc = strings.TrimSpace(d)
+            break
+        }
+        tok, tail, _ := strings.Cut(rest, " ")
+        rest = strings.TrimSpace(tail)
+        key, val, ok := strings.Cut(tok, "=")
+        if !ok {
+            return fmt.Errorf("无法识别的变量属性: %s", tok)
+        }
+        switch key {
+        case "type":
+            switch val {
+            case TypeString, TypeInt, TypePort:
+                v.Type = val
+            default:
+                return fmt.Errorf("不支持的变量类型: %s", val)
+            }
+        case "pattern":
+            re, err := regexp.Compile("^(?:" + val + ")$")
+            if err != nil {
+                return fmt.Errorf("变量 %s 的 pattern 无效: %w", name, err)
+            }
+            v.Pattern = re
+        case "default":
+            v.Default, v.HasDefault = val, true
+        default:
+            return fmt.Errorf("无法识别的变量属性: %s", key)
+        }
+    }
+    return nil
+}
+
+func (v Var) Validate(val string) error {
+    if strings.ContainsAny(val, "\"\\\r\n") {
+        return fmt.Errorf("变量 %s 不能包含引号、反斜杠或换行", v.Name)
+    }
+    switch v.Type {
+    case TypeInt:
+        if _, err := strconv.Atoi(val); err != nil {
+            return fmt.Errorf("变量 %s 需要整数: %s", v.Name, val)
+        }
+    case TypePort:
+        n, err := strconv.Atoi(val)
+        if err != nil || n <= 0 || n > 65535 {
+            return fmt.Errorf("变量 %s 需要 1-65535 的端口: %s", v.Name, val)
+        }
+    }
+    if v.Pattern != nil && !v.Pattern.MatchString(val) {
+        return fmt.Errorf("变量 %s 的值 %q 不符合格式 %s", v.Name, val, strings.TrimSuffix(strings.TrimPrefix(v.Pattern.String(), "^(?:"), ")$"))
+    }
+    return nil
+}
+
+func (t *Template) Resolve(values map[string]string) (map[string]string, error) {
+    out := make(map[string]string, len(t.Vars))
+    for _, v := range t.Vars {
+        val, ok := values[v.Name]
+        if !ok || val == "" {
+            if !v.HasDefault {
+                return nil, fmt.Errorf("变量 %s 未填写", v.Name)
+            }
+            val = v.Default
+        }
+        if err := v.Validate(val); err != nil {
+            return nil, err
+        }
+        out[v.Name] = val
+    }
+    return out, nil
+}
+
+func (t *Template) Render(values map[string]string) (string, error) {
+    resolved, err := t.Resolve(values)
+    if err != nil {
+        return "", err
+    }
+    var out strings.Builder
+    for _, line := range strings.SplitAfter(t.Source, "\n") {
+        if strings.HasPrefix(strings.TrimSpace(line), "#") {
+            out.WriteString(line)
+            continue
+        }
+        out.WriteString(placeholderRe.ReplaceAllStringFunc(line, func(s string) string {
+            return resolved[placeholderRe.FindStringSubmatch(s)[1]]
+        }))
+    }
+    return out.String(), nil
+}
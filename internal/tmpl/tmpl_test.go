@@ -0,0 +1,145 @@
+package tmpl
+
+import (
+    "strings"
+    "testing"
+)
+
+const sample = `# @var port type=port desc=本地 SSH 端口
+# @var name pattern=[a-z][a-z0-9-]* desc=代理名称
+serverAddr = "{{server:frps.example.com}}"
+
+[[proxies]]
+name = "{{name}}"
+type = "tcp"
+localPort = {{port:22}}
+remotePort = {{remote}}
+# {{comment}} stays as is
+`
+
+func TestParse(t *testing.T) {
+    tpl, err := Parse(sample)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []Var{
+        {Name: "port", Type: TypePort, Default: "22", HasDefault: true, Desc: "本地 SSH 端口"},
+        {Name: "name", Type: TypeString, Desc: "代理名称"},
+        {Name: "server", Type: TypeString, Default: "frps.example.com", HasDefault: true},
+        {Name: "remote", Type: TypeString},
+    }
+    if len(tpl.Vars) != len(want) {
+        t.Fatalf("vars = %+v", tpl.Vars)
+    }
+    for i, w := range want {
+        got := tpl.Vars[i]
+        if got.Name != w.Name || got.Type != w.Type || got.Default != w.Default || got.HasDefault != w.HasDefault || got.Desc != w.Desc {
+            t.Errorf("var %d = %+v, want %+v", i, got, w)
+        }
+    }
+    if tpl.Vars[1].Pattern == nil || tpl.Vars[1].Pattern.String() != "^(?:[a-z][a-z0-9-]*)$" {
+        t.Errorf("pattern = %v", tpl.Vars[1].Pattern)
+    }
+}
+
+func TestParseErrors(t *testing.T) {
+    cases := map[string]string{
+        "inconsistent default": "a = {{x:1}}\nb = {{x:2}}\n",
+        "bad type":             "# @var x type=float\n",
+        "bad name":             "# @var 1x\n",
+        "bad pattern":          "# @var x pattern=[\n",
+        "unknown attr":         "# @var x color=red\n",
+        "attr without value":   "# @var x required\n",
+        "bad default":          "# @var p type=port\nport = {{p:70000}}\n",
+        "bad declared default": "# @var p type=int default=abc\n",
+    }
+    for name, src := range cases {
+        if _, err := Parse(src); err == nil {
+            t.Errorf("%s: accepted", name)
+        }
+    }
+}
+
+func TestValidate(t *testing.T) {
+    cases := []struct {
+        v   Var
+        val string
+        ok  bool
+    }{
+        {Var{Name: "s", Type: TypeString}, "anything", true},
+        {Var{Name: "s", Type: TypeString}, `a"b`, false},
+        {Var{Name: "s", Type: TypeString}, `a\b`, false},
+        {Var{Name: "s", Type: TypeString}, "a\nb", false},
+        {Var{Name: "i", Type: TypeInt}, "-3", true},
+        {Var{Name: "i", Type: TypeInt}, "3x", false},
+        {Var{Name: "p", Type: TypePort}, "65535", true},
+        {Var{Name: "p", Type: TypePort}, "0", false},
+        {Var{Name: "p", Type: TypePort}, "65536", false},
+    }
+    for _, c := range cases {
+        if err := c.v.Validate(c.val); (err == nil) != c.ok {
+            t.Errorf("%s(%s) %q: err = %v", c.v.Name, c.v.Type, c.val, err)
+        }
+    }
+
+    tpl, err := Parse("# @var name pattern=[a-z]+\nname = \"{{name}}\"\n")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := tpl.Vars[0].Validate("ssh"); err != nil {
+        t.Error(err)
+    }
+    if err := tpl.Vars[0].Validate("ssh2"); err == nil {
+        t.Error("pattern is not anchored")
+    }
+}
+
+func TestResolve(t *testing.T) {
+    tpl, err := Parse(sample)
+    if err != nil {
+        t.Fatal(err)
+    }
+    got, err := tpl.Resolve(map[string]string{"name": "ssh", "remote": "6000", "port": ""})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got["port"] != "22" || got["server"] != "frps.example.com" || got["name"] != "ssh" || got["remote"] != "6000" {
+        t.Fatalf("resolved = %v", got)
+    }
+
+    for _, values := range []map[string]string{
+        {"remote": "6000"},
+        {"name": "SSH", "remote": "6000"},
+        {"name": "ssh", "remote": "6000", "port": "99999"},
+    } {
+        if _, err := tpl.Resolve(values); err == nil {
+            t.Errorf("%v accepted", values)
+        }
+    }
+}
+
+func TestRender(t *testing.T) {
+    tpl, err := Parse(sample)
+    if err != nil {
+        t.Fatal(err)
+    }
+    out, err := tpl.Render(map[string]string{"name": "ssh", "remote": "6000", "server": "frps.lan"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, want := range []string{
+        `serverAddr = "frps.lan"` + "\n",
+        `name = "ssh"` + "\n",
+        "localPort = 22\n",
+        "remotePort = 6000\n",
+        "# {{comment}} stays as is\n",
+        "# @var port type=port desc=本地 SSH 端口\n",
+    } {
+        if !strings.Contains(out, want) {
+            t.Errorf("missing %q in:\n%s", want, out)
+        }
+    }
+    if _, err := tpl.Render(map[string]string{"name": "ssh"}); err == nil {
+        t.Error("missing variable accepted")
+    }
+}
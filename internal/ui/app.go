@@ -14,17 +14,21 @@ import (
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"frpcx/internal/config"
 	"frpcx/internal/frpc"
+	"frpcx/internal/logs"
+	"frpcx/internal/metrics"
+	"frpcx/internal/netwatch"
 )
 
 const singleProfileName = "default"
 
+var log = logs.For("ui")
+
 type frpcForm struct {
 	ServerAddr string
 	ServerPort string
@@ -33,6 +37,13 @@ type frpcForm struct {
 	LocalPort  string
 	Domain     string
 	RemotePort string
+	ProxyURL   string
+	SysProxy   bool
+	Transport  frpc.TransportOptions
+	AuthMethod string
+	OIDC       frpc.OIDCOptions
+	OIDCSecret string
+	Visitors   []frpc.VisitorInfo
 }
 
 type App struct {
@@ -41,8 +52,14 @@ type App struct {
 	cfg *config.AppConfig
 	mgr *frpc.Manager
 
+	collector    *metrics.Collector
+	metricsPanel *metricsPanel
+	notifier     *notifier
+	netwatch     *netwatch.Watcher
+
 	statusDot    *canvas.Text
 	profileLabel *widget.Label
+	versionLabel *widget.Label
 	hintLabel    *widget.Label
 	errorLabel   *widget.Label
 	logEntry     *widget.Entry
@@ -54,12 +71,23 @@ type App struct {
 	localPortEntry  *widget.Entry
 	domainEntry     *widget.Entry
 	remotePortEntry *widget.Entry
+	proxyURLEntry   *widget.Entry
+	sysProxyCheck   *widget.Check
+	transport       frpc.TransportOptions
+	authMethod      string
+	oidc            frpc.OIDCOptions
+	oidcSecret      string
+	visitors        []frpc.VisitorInfo
 
 	domainRow     fyne.CanvasObject
 	remotePortRow fyne.CanvasObject
 
-	autoSaveMu    sync.Mutex
-	autoSaveTimer *time.Timer
+	autoSaveMu       sync.Mutex
+	autoSaveTimer    *time.Timer
+	suppressAutoSave bool
+
+	logViewer *logViewer
+	tray      *trayMenu
 }
 
 func Run(cfg *config.AppConfig) {
@@ -69,20 +97,36 @@ func Run(cfg *config.AppConfig) {
 	a.Settings().SetTheme(newSuidaoTheme())
 	win := a.NewWindow("穿透助手")
 	mgr := frpc.NewManager(cfg)
+	collector := metrics.NewCollector(mgr, cfg.Metrics)
+	collector.Start()
+	if err := collector.Apply(cfg.Metrics); err != nil {
+		log.Error("开启 metrics 端点失败", "listen", cfg.Metrics.Listen, "err", err)
+	}
+	defer collector.Stop()
 
-	u := &App{app: a, win: win, cfg: cfg, mgr: mgr}
+	u := &App{app: a, win: win, cfg: cfg, mgr: mgr, collector: collector}
+	u.notifier = newNotifier(a, cfg.Notify)
+	mgr.Subscribe(u.notifier.Handle)
 	u.build()
 	u.setupTray()
 	u.startStatusTicker()
+	go u.refreshFrpcVersion()
+	go u.onLaunch()
+	u.applyNetWatch()
 
 	win.Resize(fyne.NewSize(700, 470))
 	win.ShowAndRun()
+	if u.netwatch != nil {
+		u.netwatch.Stop()
+	}
+	mgr.Stop()
 }
 
 func (u *App) build() {
 	u.statusDot = canvas.NewText("●", statusColor("stopped"))
 	u.statusDot.TextSize = 16
 	u.profileLabel = widget.NewLabelWithStyle(singleProfileName, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	u.versionLabel = widget.NewLabel("")
 	u.hintLabel = widget.NewLabel("修改参数后自动保存")
 	u.errorLabel = widget.NewLabel("")
 
@@ -94,6 +138,10 @@ func (u *App) build() {
 	u.localPortEntry = widget.NewEntry()
 	u.domainEntry = widget.NewEntry()
 	u.remotePortEntry = widget.NewEntry()
+	u.proxyURLEntry = widget.NewEntry()
+	u.sysProxyCheck = widget.NewCheck("使用系统代理", func(bool) {
+		u.scheduleAutoSave(u.readForm())
+	})
 
 	u.proxyTypeSelect = widget.NewSelect([]string{"http", "tcp"}, func(string) {
 		u.updateProxyTypeUI()
@@ -106,14 +154,7 @@ func (u *App) build() {
 	u.localPortEntry.SetPlaceHolder("例如 8000")
 	u.domainEntry.SetPlaceHolder("例如 frp.iqei.cn")
 	u.remotePortEntry.SetPlaceHolder("TCP 时必填，例如 6000")
-
-	u.serverAddrEntry.SetText(formData.ServerAddr)
-	u.serverPortEntry.SetText(formData.ServerPort)
-	u.tokenEntry.SetText(formData.Token)
-	u.localPortEntry.SetText(formData.LocalPort)
-	u.domainEntry.SetText(formData.Domain)
-	u.remotePortEntry.SetText(formData.RemotePort)
-	u.proxyTypeSelect.SetSelected(formData.ProxyType)
+	u.proxyURLEntry.SetPlaceHolder("可选，例如 http://127.0.0.1:7890 或 socks5://127.0.0.1:1080")
 
 	tokenShown := false
 	var tokenToggle *widget.Button
@@ -137,6 +178,7 @@ func (u *App) build() {
 	u.localPortEntry.OnChanged = onEntryChanged
 	u.domainEntry.OnChanged = onEntryChanged
 	u.remotePortEntry.OnChanged = onEntryChanged
+	u.proxyURLEntry.OnChanged = onEntryChanged
 
 	rowServer := container.NewGridWithColumns(4,
 		widget.NewLabel("服务器"), u.serverAddrEntry,
@@ -150,8 +192,11 @@ func (u *App) build() {
 	)
 	u.domainRow = container.NewGridWithColumns(2, widget.NewLabel("域名"), u.domainEntry)
 	u.remotePortRow = container.NewGridWithColumns(2, widget.NewLabel("远程端口"), u.remotePortEntry)
+	rowProxy := container.NewGridWithColumns(2, widget.NewLabel("代理"), container.NewBorder(nil, nil, nil, u.sysProxyCheck, u.proxyURLEntry))
 
-	u.updateProxyTypeUI()
+	u.suppressAutoSave = true
+	u.fillForm(formData)
+	u.suppressAutoSave = false
 
 	saveBtn := widget.NewButtonWithIcon("保存", theme.DocumentSaveIcon(), func() {
 		if err := u.saveFormToGeneratedToml(u.readForm()); err != nil {
@@ -166,11 +211,12 @@ func (u *App) build() {
 			return
 		}
 		u.errorLabel.SetText("")
-		u.mgr.StartAuto()
-		go u.watchStartResult()
+		log.Info("用户启动")
+		go u.startChecked()
 	})
 	stopBtn := widget.NewButtonWithIcon("停止", theme.MediaStopIcon(), func() {
-		u.mgr.Stop()
+		log.Info("用户停止")
+		go u.mgr.Stop()
 	})
 	actionsRow := container.NewGridWithColumns(3, saveBtn, startBtn, stopBtn)
 
@@ -178,12 +224,41 @@ func (u *App) build() {
 	u.logEntry.SetMinRowsVisible(3)
 	u.logEntry.Wrapping = fyne.TextWrapOff
 	u.logEntry.Disable()
-	logsCard := widget.NewCard("日志", "", u.logEntry)
-
-	statusRow := container.NewHBox(u.statusDot, widget.NewLabel(" "), u.profileLabel, layout.NewSpacer())
-	configCard := widget.NewCard("", "", container.NewVBox(rowServer, rowToken, rowType, u.domainRow, u.remotePortRow))
+	logsBtn := widget.NewButtonWithIcon("全部日志", theme.ListIcon(), u.showLogViewer)
+	logsCard := widget.NewCard("日志", "", container.NewBorder(nil, container.NewHBox(layout.NewSpacer(), logsBtn), nil, nil, u.logEntry))
+
+	settingsBtn := widget.NewButtonWithIcon("", theme.SettingsIcon(), u.showSettings)
+	hooksBtn := widget.NewButtonWithIcon("钩子", theme.MailForwardIcon(), u.showHooksEditor)
+	versionsBtn := widget.NewButtonWithIcon("frpc", theme.StorageIcon(), u.showVersions)
+	transportBtn := widget.NewButtonWithIcon("传输", theme.MenuIcon(), u.showTransportEditor)
+	authBtn := widget.NewButtonWithIcon("认证", theme.AccountIcon(), u.showAuthEditor)
+	visitorsBtn := widget.NewButtonWithIcon("访问者", theme.LoginIcon(), u.showVisitorEditor)
+	templateBtn := widget.NewButtonWithIcon("模板", theme.ContentCopyIcon(), u.showTemplateEditor)
+	statusRow := container.NewHBox(u.statusDot, widget.NewLabel(" "), u.profileLabel, u.versionLabel, layout.NewSpacer(), visitorsBtn, templateBtn, authBtn, transportBtn, versionsBtn, hooksBtn, settingsBtn)
+	configCard := widget.NewCard("", "", container.NewVBox(rowServer, rowToken, rowProxy, rowType, u.domainRow, u.remotePortRow))
+
+	u.metricsPanel = newMetricsPanel()
+
+	u.win.SetContent(container.NewVBox(statusRow, configCard, u.hintLabel, u.errorLabel, actionsRow, u.metricsPanel.card, logsCard))
+}
 
-	u.win.SetContent(container.NewVBox(statusRow, configCard, u.hintLabel, u.errorLabel, actionsRow, logsCard))
+func (u *App) fillForm(formData frpcForm) {
+	u.serverAddrEntry.SetText(formData.ServerAddr)
+	u.serverPortEntry.SetText(formData.ServerPort)
+	u.tokenEntry.SetText(formData.Token)
+	u.localPortEntry.SetText(formData.LocalPort)
+	u.domainEntry.SetText(formData.Domain)
+	u.remotePortEntry.SetText(formData.RemotePort)
+	u.proxyTypeSelect.SetSelected(formData.ProxyType)
+	u.proxyURLEntry.SetText(formData.ProxyURL)
+	u.sysProxyCheck.SetChecked(formData.SysProxy)
+	u.transport = formData.Transport
+	u.authMethod = formData.AuthMethod
+	u.oidc = formData.OIDC
+	u.oidcSecret = formData.OIDCSecret
+	u.visitors = formData.Visitors
+	u.updateProxyTypeUI()
+	u.updateAuthUI()
 }
 
 func (u *App) updateProxyTypeUI() {
@@ -205,6 +280,13 @@ func (u *App) readForm() frpcForm {
 		LocalPort:  strings.TrimSpace(u.localPortEntry.Text),
 		Domain:     strings.TrimSpace(u.domainEntry.Text),
 		RemotePort: strings.TrimSpace(u.remotePortEntry.Text),
+		ProxyURL:   strings.TrimSpace(u.proxyURLEntry.Text),
+		SysProxy:   u.sysProxyCheck.Checked,
+		Transport:  u.transport,
+		AuthMethod: u.authMethod,
+		OIDC:       u.oidc,
+		OIDCSecret: u.oidcSecret,
+		Visitors:   u.visitors,
 	}
 }
 
@@ -217,10 +299,15 @@ func defaultForm() frpcForm {
 		LocalPort:  "8000",
 		Domain:     "frp.iqei.cn",
 		RemotePort: "6000",
+		Transport:  frpc.DefaultTransport(),
+		AuthMethod: frpc.AuthToken,
 	}
 }
 
 func (u *App) scheduleAutoSave(form frpcForm) {
+	if u.suppressAutoSave {
+		return
+	}
 	u.autoSaveMu.Lock()
 	defer u.autoSaveMu.Unlock()
 
@@ -267,6 +354,34 @@ func (u *App) saveFormToGeneratedToml(form frpcForm) error {
 		return fmt.Errorf("本地端口无效")
 	}
 
+	if form.ProxyURL != "" {
+		if err := frpc.ValidateProxyURL(form.ProxyURL); err != nil {
+			u.setHint("未保存：" + err.Error())
+			return err
+		}
+	}
+
+	if err := form.Transport.Validate(); err != nil {
+		u.setHint("未保存：传输设置无效")
+		return fmt.Errorf("传输设置无效: %w", err)
+	}
+
+	if form.AuthMethod == frpc.AuthOIDC {
+		if err := form.OIDC.Validate(); err != nil {
+			u.setHint("未保存：" + err.Error())
+			return err
+		}
+		if err := config.SetSecret(frpc.OIDCSecretKey(singleProfileName), form.OIDCSecret); err != nil {
+			u.setHint("未保存：写入密钥库失败")
+			return fmt.Errorf("写入密钥库失败")
+		}
+	}
+
+	if err := frpc.ValidateVisitors(form.Visitors); err != nil {
+		u.setHint("未保存：访问者设置无效")
+		return err
+	}
+
 	domain := form.Domain
 	remotePort := 0
 	if form.ProxyType == "tcp" {
@@ -288,29 +403,53 @@ func (u *App) saveFormToGeneratedToml(form frpcForm) error {
 		return fmt.Errorf("无法创建配置目录")
 	}
 
-	content := buildFrpcToml(form.ServerAddr, serverPort, form.Token, singleProfileName, form.ProxyType, localPort, domain, remotePort)
+	profile := config.Profile{}
+	if p := u.currentProfile(); p != nil {
+		profile = *p
+	}
+	content := buildFrpcToml(form.ServerAddr, serverPort, form.AuthMethod, form.Token, form.OIDC, form.ProxyURL, form.Transport, singleProfileName, form.ProxyType, localPort, domain, remotePort, form.Visitors)
+	if profile.Template != "" {
+		if formToml(u.loadFormFromCurrentProfile()) == content {
+			profile.UseSystemProxy = form.SysProxy
+			u.cfg.ActiveProfile = singleProfileName
+			u.cfg.Profiles = []config.Profile{profile}
+			if err := config.Save(u.cfg); err != nil {
+				u.setHint("未保存：写入应用配置失败")
+				return fmt.Errorf("写入应用配置失败")
+			}
+			u.mgr.SetConfig(u.cfg)
+			u.setHint("已自动保存")
+			return nil
+		}
+		log.Info("表单已修改，配置脱离模板", "template", profile.Template)
+		profile.Template = ""
+		profile.Vars = nil
+	}
 	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		log.Error("写入 TOML 失败", "path", cfgPath, "err", err)
 		u.setHint("未保存：写入 TOML 失败")
 		return fmt.Errorf("写入 TOML 失败")
 	}
 
+	profile.Name = singleProfileName
+	profile.Enabled = true
+	profile.FrpcPath = ""
+	profile.ConfigPath = cfgPath
+	profile.UseSystemProxy = form.SysProxy
+	profile.RequireStatus = false
+	profile.StartTimeoutSec = 8
+	profile.HealthTimeoutSec = 3
+	profile.StatusTimeoutSec = 0
+	profile.StatusIntervalSec = 0
+
 	u.cfg.ActiveProfile = singleProfileName
-	u.cfg.Profiles = []config.Profile{{
-		Name:              singleProfileName,
-		Enabled:           true,
-		FrpcPath:          "",
-		ConfigPath:        cfgPath,
-		RequireStatus:     false,
-		StartTimeoutSec:   8,
-		HealthTimeoutSec:  3,
-		StatusTimeoutSec:  0,
-		StatusIntervalSec: 0,
-	}}
+	u.cfg.Profiles = []config.Profile{profile}
 	if err := config.Save(u.cfg); err != nil {
 		u.setHint("未保存：写入应用配置失败")
 		return fmt.Errorf("写入应用配置失败")
 	}
 	u.mgr.SetConfig(u.cfg)
+	log.Debug("配置已保存", "path", cfgPath, "type", form.ProxyType)
 	u.setHint("已自动保存")
 	return nil
 }
@@ -355,6 +494,16 @@ func isEmptyForm(form frpcForm) bool {
 		form.RemotePort == ""
 }
 
+func formToml(form frpcForm) string {
+	serverPort, _ := parsePort(form.ServerPort)
+	localPort, _ := parsePort(form.LocalPort)
+	remotePort := 0
+	if form.ProxyType == "tcp" {
+		remotePort, _ = parsePort(form.RemotePort)
+	}
+	return buildFrpcToml(form.ServerAddr, serverPort, form.AuthMethod, form.Token, form.OIDC, form.ProxyURL, form.Transport, singleProfileName, form.ProxyType, localPort, form.Domain, remotePort, form.Visitors)
+}
+
 func parsePort(s string) (int, error) {
 	v, err := strconv.Atoi(strings.TrimSpace(s))
 	if err != nil {
@@ -378,7 +527,7 @@ func generatedConfigPath() (string, error) {
 	return filepath.Join(out, "single.toml"), nil
 }
 
-func buildFrpcToml(serverAddr string, serverPort int, token, proxyName, proxyType string, localPort int, domain string, remotePort int) string {
+func buildFrpcToml(serverAddr string, serverPort int, authMethod, token string, oidc frpc.OIDCOptions, proxyURL string, transport frpc.TransportOptions, proxyName, proxyType string, localPort int, domain string, remotePort int, visitors []frpc.VisitorInfo) string {
 	var b strings.Builder
 	b.WriteString("serverAddr = \"")
 	b.WriteString(escapeTomlString(serverAddr))
@@ -387,12 +536,13 @@ func buildFrpcToml(serverAddr string, serverPort int, token, proxyName, proxyTyp
 	b.WriteString(strconv.Itoa(serverPort))
 	b.WriteString("\n")
 
-	if token != "" {
-		b.WriteString("auth.method = \"token\"\n")
-		b.WriteString("auth.token = \"")
-		b.WriteString(escapeTomlString(token))
+	writeAuthToml(&b, authMethod, token, oidc)
+	if proxyURL != "" {
+		b.WriteString("transport.proxyURL = \"")
+		b.WriteString(escapeTomlString(proxyURL))
 		b.WriteString("\"\n")
 	}
+	writeTransportToml(&b, transport)
 
 	b.WriteString("\n[[proxies]]\n")
 	b.WriteString("name = \"")
@@ -415,6 +565,7 @@ func buildFrpcToml(serverAddr string, serverPort int, token, proxyName, proxyTyp
 		b.WriteString(escapeTomlString(domain))
 		b.WriteString("\"]\n")
 	}
+	writeVisitorsToml(&b, visitors)
 
 	return b.String()
 }
@@ -432,6 +583,39 @@ func (u *App) loadFormFromCurrentProfile() frpcForm {
 	if p == nil || strings.TrimSpace(p.ConfigPath) == "" {
 		return out
 	}
+	out.SysProxy = p.UseSystemProxy
+	if p.Template != "" {
+		if path, err := frpc.RenderProfile(p); err == nil {
+			p.ConfigPath = path
+		} else {
+			log.Warn("模板渲染失败", "template", p.Template, "err", err)
+		}
+	}
+	if info, err := frpc.LoadClientInfo(p.ConfigPath); err == nil {
+		out.Transport = info.Transport
+		out.Visitors = info.Visitors
+		if len(info.Proxies) > 0 {
+			px := info.Proxies[0]
+			out.ProxyType = px.Type
+			if px.LocalPort != 0 {
+				out.LocalPort = strconv.Itoa(px.LocalPort)
+			}
+			if px.RemotePort != 0 {
+				out.RemotePort = strconv.Itoa(px.RemotePort)
+			}
+			if len(px.CustomDomains) > 0 {
+				out.Domain = px.CustomDomains[0]
+			}
+		}
+		if info.AuthMethod == frpc.AuthOIDC {
+			out.AuthMethod = frpc.AuthOIDC
+			out.OIDC = info.OIDC
+			out.OIDCSecret, _ = config.GetSecret(frpc.OIDCSecretKey(p.Name))
+			if out.OIDCSecret == "" && info.OIDC.ClientSecret != frpc.OIDCSecretRef() {
+				out.OIDCSecret = info.OIDC.ClientSecret
+			}
+		}
+	}
 
 	b, err := os.ReadFile(p.ConfigPath)
 	if err != nil {
@@ -450,16 +634,8 @@ func (u *App) loadFormFromCurrentProfile() frpcForm {
 			out.ServerPort = normalizeIntText(val)
 		case "auth.token":
 			out.Token = unquoteTomlValue(val)
-		case "type":
-			out.ProxyType = unquoteTomlValue(val)
-		case "localPort":
-			out.LocalPort = normalizeIntText(val)
-		case "remotePort":
-			out.RemotePort = normalizeIntText(val)
-		case "customDomains":
-			if d := parseFirstArrayString(val); d != "" {
-				out.Domain = d
-			}
+		case "transport.proxyURL":
+			out.ProxyURL = unquoteTomlValue(val)
 		}
 	}
 	if out.ProxyType == "" {
@@ -501,19 +677,6 @@ func normalizeIntText(v string) string {
 	return strconv.Itoa(n)
 }
 
-func parseFirstArrayString(v string) string {
-	v = strings.TrimSpace(v)
-	if !strings.HasPrefix(v, "[") || !strings.HasSuffix(v, "]") {
-		return ""
-	}
-	inner := strings.TrimSpace(v[1 : len(v)-1])
-	if inner == "" {
-		return ""
-	}
-	first := strings.SplitN(inner, ",", 2)[0]
-	return unquoteTomlValue(strings.TrimSpace(first))
-}
-
 func (u *App) currentProfile() *config.Profile {
 	if len(u.cfg.Profiles) == 0 {
 		return nil
@@ -542,24 +705,13 @@ func (u *App) startStatusTicker() {
 				if len(snap.LogLines) > 0 {
 					u.logEntry.SetText(strings.Join(snap.LogLines, "\n"))
 				}
+				u.refreshMetrics()
+				u.refreshTray()
 			})
 		}
 	}()
 }
 
-func (u *App) setupTray() {
-	if desk, ok := u.app.(desktop.App); ok {
-		showItem := fyne.NewMenuItem("显示", func() { u.win.Show() })
-		hideItem := fyne.NewMenuItem("隐藏", func() { u.win.Hide() })
-		startItem := fyne.NewMenuItem("启动", func() { u.mgr.StartAuto() })
-		stopItem := fyne.NewMenuItem("停止", func() { u.mgr.Stop() })
-		quitItem := fyne.NewMenuItem("退出", func() { u.app.Quit() })
-
-		menu := fyne.NewMenu("穿透助手", showItem, hideItem, startItem, stopItem, quitItem)
-		desk.SetSystemTrayMenu(menu)
-	}
-}
-
 func (u *App) setHint(msg string) {
 	fyne.Do(func() {
 		u.hintLabel.SetText(msg)
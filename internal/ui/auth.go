@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/frpc"
+)
+
+func (u *App) showAuthEditor() {
+	w := u.app.NewWindow("认证设置")
+
+	method := widget.NewSelect([]string{frpc.AuthToken, frpc.AuthOIDC}, nil)
+	clientID := widget.NewEntry()
+	secret := widget.NewPasswordEntry()
+	audience := widget.NewEntry()
+	scope := widget.NewEntry()
+	endpoint := widget.NewEntry()
+	errLabel := widget.NewLabel("")
+
+	secret.SetPlaceHolder("保存在本机密钥库，不写入配置文件")
+	audience.SetPlaceHolder("可选")
+	scope.SetPlaceHolder("可选，多个用空格分隔")
+	endpoint.SetPlaceHolder("例如 https://idp.example.com/oauth2/token")
+
+	clientID.SetText(u.oidc.ClientID)
+	secret.SetText(u.oidcSecret)
+	audience.SetText(u.oidc.Audience)
+	scope.SetText(u.oidc.Scope)
+	endpoint.SetText(u.oidc.TokenEndpointURL)
+
+	row := func(label string, obj fyne.CanvasObject) fyne.CanvasObject {
+		return container.NewGridWithColumns(2, widget.NewLabel(label), obj)
+	}
+	oidcBox := container.NewVBox(
+		row("Client ID", clientID),
+		row("Client Secret", secret),
+		row("Audience", audience),
+		row("Scope", scope),
+		row("Token 地址", endpoint),
+	)
+	method.OnChanged = func(m string) {
+		if m == frpc.AuthOIDC {
+			oidcBox.Show()
+		} else {
+			oidcBox.Hide()
+		}
+	}
+	method.SetSelected(u.authMethod)
+
+	read := func() frpc.OIDCOptions {
+		return frpc.OIDCOptions{
+			ClientID:         strings.TrimSpace(clientID.Text),
+			Audience:         strings.TrimSpace(audience.Text),
+			Scope:            strings.TrimSpace(scope.Text),
+			TokenEndpointURL: strings.TrimSpace(endpoint.Text),
+		}
+	}
+
+	var testBtn *widget.Button
+	testBtn = widget.NewButtonWithIcon("获取 Token", theme.ConfirmIcon(), func() {
+		o := read()
+		s := secret.Text
+		testBtn.Disable()
+		errLabel.SetText("正在请求…")
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_, err := frpc.FetchOIDCToken(ctx, o, s)
+			fyne.Do(func() {
+				testBtn.Enable()
+				if err != nil {
+					errLabel.SetText(err.Error())
+					return
+				}
+				errLabel.SetText("已成功获取 Token")
+			})
+		}()
+	})
+	saveBtn := widget.NewButtonWithIcon("保存", theme.DocumentSaveIcon(), func() {
+		next := read()
+		if method.Selected == frpc.AuthOIDC {
+			if err := next.Validate(); err != nil {
+				errLabel.SetText(err.Error())
+				return
+			}
+			if secret.Text == "" {
+				errLabel.SetText("请填写 OIDC Client Secret")
+				return
+			}
+		}
+		prevMethod, prevOIDC, prevSecret := u.authMethod, u.oidc, u.oidcSecret
+		u.authMethod, u.oidc, u.oidcSecret = method.Selected, next, secret.Text
+		if err := u.saveFormToGeneratedToml(u.readForm()); err != nil {
+			u.authMethod, u.oidc, u.oidcSecret = prevMethod, prevOIDC, prevSecret
+			errLabel.SetText(err.Error())
+			return
+		}
+		u.updateAuthUI()
+		log.Info("认证设置已保存", "method", u.authMethod)
+		w.Close()
+	})
+
+	w.SetContent(container.NewVBox(
+		row("认证方式", method),
+		oidcBox,
+		errLabel,
+		container.NewHBox(testBtn, saveBtn),
+	))
+	w.Resize(fyne.NewSize(520, 0))
+	w.Show()
+}
+
+func (u *App) updateAuthUI() {
+	if u.authMethod == frpc.AuthOIDC {
+		u.tokenEntry.SetPlaceHolder("已使用 OIDC 认证")
+		u.tokenEntry.Disable()
+		return
+	}
+	u.tokenEntry.SetPlaceHolder("可选")
+	u.tokenEntry.Enable()
+}
+
+func writeAuthToml(b *strings.Builder, method, token string, o frpc.OIDCOptions) {
+	str := func(key, val string) {
+		if val != "" {
+			b.WriteString(key + " = \"" + escapeTomlString(val) + "\"\n")
+		}
+	}
+	if method == frpc.AuthOIDC {
+		b.WriteString("auth.method = \"oidc\"\n")
+		str("auth.oidc.clientID", o.ClientID)
+		str("auth.oidc.clientSecret", frpc.OIDCSecretRef())
+		str("auth.oidc.audience", o.Audience)
+		str("auth.oidc.scope", o.Scope)
+		str("auth.oidc.tokenEndpointURL", o.TokenEndpointURL)
+		return
+	}
+	if token != "" {
+		b.WriteString("auth.method = \"token\"\n")
+		str("auth.token", token)
+	}
+}
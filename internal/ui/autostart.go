@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"frpcx/internal/autostart"
+)
+
+func (u *App) onLaunch() {
+	if u.cfg.Autostart {
+		if err := autostart.Enable(); err != nil {
+			log.Warn("更新开机自启项失败", "err", err)
+		}
+	}
+
+	if u.checkOrphans() {
+		if u.cfg.AutoStartTunnel {
+			log.Warn("存在残留的 frpc 进程，跳过自动启动隧道")
+		}
+		return
+	}
+	if !u.cfg.AutoStartTunnel {
+		return
+	}
+	log.Info("自动启动隧道")
+	u.startTunnel()
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"frpcx/internal/config"
+	"frpcx/internal/frpc"
+)
+
+func (u *App) refreshFrpcVersion() {
+	p := u.currentProfile()
+	if p == nil {
+		return
+	}
+	profile := *p
+	text := "frpc 版本未知"
+	if v, err := frpc.ProfileVersion(&profile); err == nil {
+		text = "frpc " + v
+	} else {
+		log.Debug("获取 frpc 版本失败", "profile", profile.Name, "err", err)
+	}
+	fyne.Do(func() {
+		u.versionLabel.SetText(text)
+	})
+}
+
+func (u *App) startChecked() {
+	p := u.currentProfile()
+	if p == nil {
+		u.startTunnel()
+		return
+	}
+	profile := *p
+	c, err := frpc.CheckProfileCompat(&profile)
+	var incompatible *frpc.IncompatibleError
+	switch {
+	case errors.As(err, &incompatible):
+		fyne.Do(func() { u.errorLabel.SetText(err.Error()) })
+		return
+	case err != nil:
+		log.Warn("frpc 兼容性检查失败", "profile", profile.Name, "err", err)
+	case c.NeedIni && !profile.ConvertToIni:
+		fyne.Do(func() { u.confirmIni(profile.Name, c.Version) })
+		return
+	case len(c.Warnings) > 0:
+		u.setHint(strings.Join(c.Warnings, "；"))
+	}
+	u.startTunnel()
+}
+
+func (u *App) confirmIni(profile, version string) {
+	msg := "当前 frpc 版本为 " + version + "，不支持 TOML 配置（需要 0.52.0 及以上）。\n是否自动生成 INI 配置后启动？不支持的选项会被忽略并记录到日志。"
+	u.win.Show()
+	dialog.ShowConfirm("frpc 版本过旧", msg, func(ok bool) {
+		if !ok {
+			u.errorLabel.SetText("frpc " + version + " 不支持 TOML 配置，请升级 frpc")
+			return
+		}
+		go func() {
+			u.updateConfig(func(cfg *config.AppConfig) {
+				for i := range cfg.Profiles {
+					if cfg.Profiles[i].Name == profile {
+						cfg.Profiles[i].ConvertToIni = true
+					}
+				}
+			})
+			u.startTunnel()
+		}()
+	}, u.win)
+}
+
+func (u *App) startTunnel() {
+	u.mgr.StartAuto()
+	u.watchStartResult()
+}
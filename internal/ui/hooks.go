@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/config"
+	"frpcx/internal/hooks"
+)
+
+var hookEventLabels = map[string]string{
+	hooks.PreStart:   "启动前",
+	hooks.PostReady:  "就绪后",
+	hooks.OnFailure:  "失败时",
+	hooks.PostStop:   "停止后",
+	hooks.OnFailover: "切换时",
+}
+
+type hookRow struct {
+	event   *widget.Select
+	target  *widget.Entry
+	timeout *widget.Entry
+}
+
+func (u *App) showHooksEditor() {
+	p := u.currentProfile()
+	if p == nil {
+		u.errorLabel.SetText("请先填写并保存配置")
+		return
+	}
+
+	w := u.app.NewWindow("钩子")
+	labels := make([]string, 0, len(hooks.Events))
+	for _, e := range hooks.Events {
+		labels = append(labels, hookEventLabels[e])
+	}
+
+	var rows []*hookRow
+	list := container.NewVBox()
+	errLabel := widget.NewLabel("")
+
+	var addRow func(h config.Hook)
+	addRow = func(h config.Hook) {
+		r := &hookRow{
+			event:   widget.NewSelect(labels, nil),
+			target:  widget.NewEntry(),
+			timeout: widget.NewEntry(),
+		}
+		r.event.SetSelected(hookEventLabels[h.Event])
+		if h.URL != "" {
+			r.target.SetText(h.URL)
+		} else {
+			r.target.SetText(h.Command)
+		}
+		r.target.SetPlaceHolder("shell 命令，或 http(s):// 地址（POST JSON）")
+		if h.TimeoutSec > 0 {
+			r.timeout.SetText(strconv.Itoa(h.TimeoutSec))
+		}
+		r.timeout.SetPlaceHolder("超时秒数")
+
+		var line *fyne.Container
+		removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			for i := range rows {
+				if rows[i] == r {
+					rows = append(rows[:i], rows[i+1:]...)
+					break
+				}
+			}
+			list.Remove(line)
+		})
+		line = container.NewBorder(nil, nil, r.event, container.NewHBox(r.timeout, removeBtn), r.target)
+		rows = append(rows, r)
+		list.Add(line)
+	}
+	for _, h := range p.Hooks {
+		addRow(h)
+	}
+
+	addBtn := widget.NewButtonWithIcon("添加", theme.ContentAddIcon(), func() {
+		addRow(config.Hook{Event: hooks.PostReady, TimeoutSec: 10})
+	})
+	saveBtn := widget.NewButtonWithIcon("保存", theme.DocumentSaveIcon(), func() {
+		out := make([]config.Hook, 0, len(rows))
+		for _, r := range rows {
+			target := strings.TrimSpace(r.target.Text)
+			if target == "" {
+				continue
+			}
+			h := config.Hook{Event: hookEventByLabel(r.event.Selected)}
+			if h.Event == "" {
+				errLabel.SetText("请选择钩子事件")
+				return
+			}
+			if t := strings.TrimSpace(r.timeout.Text); t != "" {
+				sec, err := strconv.Atoi(t)
+				if err != nil || sec <= 0 {
+					errLabel.SetText("超时秒数无效: " + t)
+					return
+				}
+				h.TimeoutSec = sec
+			}
+			if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+				h.URL = target
+			} else {
+				h.Command = target
+			}
+			out = append(out, h)
+		}
+		u.updateConfig(func(cfg *config.AppConfig) {
+			for i := range cfg.Profiles {
+				if cfg.Profiles[i].Name == p.Name {
+					cfg.Profiles[i].Hooks = out
+				}
+			}
+		})
+		log.Info("钩子已保存", "profile", p.Name, "count", len(out))
+		w.Close()
+	})
+
+	help := widget.NewLabel("命令通过环境变量 FRPCX_EVENT、FRPCX_PROFILE、FRPCX_FROM_PROFILE、FRPCX_MESSAGE、FRPCX_CONFIG_PATH 获取事件信息；输出写入日志。")
+	help.Wrapping = fyne.TextWrapWord
+
+	w.SetContent(container.NewBorder(help, container.NewVBox(errLabel, container.NewHBox(addBtn, saveBtn)), nil, nil, container.NewVScroll(list)))
+	w.Resize(fyne.NewSize(640, 360))
+	w.Show()
+}
+
+func hookEventByLabel(label string) string {
+	for e, l := range hookEventLabels {
+		if l == label {
+			return e
+		}
+	}
+	return ""
+}
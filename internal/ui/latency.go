@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/frpc"
+)
+
+var strategyLabels = []struct {
+	value string
+	label string
+}{
+	{frpc.StrategyOrdered, "按顺序"},
+	{frpc.StrategyLatency, "延迟最低"},
+	{frpc.StrategyRoundRobin, "轮询"},
+}
+
+func strategyLabel(value string) string {
+	for _, s := range strategyLabels {
+		if s.value == value {
+			return s.label
+		}
+	}
+	return strategyLabels[0].label
+}
+
+func strategyValue(label string) string {
+	for _, s := range strategyLabels {
+		if s.label == label {
+			return s.value
+		}
+	}
+	return frpc.StrategyOrdered
+}
+
+func newStrategySelect(current string) *widget.Select {
+	labels := make([]string, 0, len(strategyLabels))
+	for _, s := range strategyLabels {
+		labels = append(labels, s.label)
+	}
+	sel := widget.NewSelect(labels, nil)
+	sel.SetSelected(strategyLabel(current))
+	return sel
+}
+
+func (u *App) newLatencyBox() fyne.CanvasObject {
+	list := container.NewVBox()
+	render := func(results []frpc.Latency) {
+		list.RemoveAll()
+		if len(results) == 0 {
+			list.Add(widget.NewLabel("尚未测速"))
+			return
+		}
+		for _, l := range results {
+			list.Add(widget.NewLabel(formatLatency(l)))
+		}
+	}
+	render(u.mgr.Latencies())
+
+	var btn *widget.Button
+	btn = widget.NewButtonWithIcon("测速", theme.ViewRefreshIcon(), func() {
+		btn.Disable()
+		go func() {
+			results := u.mgr.MeasureLatency()
+			fyne.Do(func() {
+				render(results)
+				btn.Enable()
+			})
+		}()
+	})
+	return container.NewBorder(nil, nil, nil, btn, list)
+}
+
+func formatLatency(l frpc.Latency) string {
+	if !l.OK() {
+		return fmt.Sprintf("%s  %s  不可达: %s", l.Profile, l.Addr, l.Err)
+	}
+	return fmt.Sprintf("%s  %s  %s", l.Profile, l.Addr, formatRTT(l.RTT))
+}
+
+func formatRTT(d time.Duration) string {
+	if d < time.Millisecond {
+		return "<1ms"
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
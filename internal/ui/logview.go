@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/diag"
+	"frpcx/internal/logs"
+)
+
+const logLevelAll = "全部"
+
+type logViewer struct {
+	u      *App
+	win    fyne.Window
+	list   *widget.List
+	level  *widget.Select
+	search *widget.Entry
+	follow *widget.Check
+	count  *widget.Label
+	shown  []logs.Entry
+	stop   chan struct{}
+}
+
+func (u *App) showLogViewer() {
+	if u.logViewer != nil {
+		u.logViewer.win.RequestFocus()
+		return
+	}
+
+	v := &logViewer{u: u, stop: make(chan struct{})}
+	v.win = u.app.NewWindow("日志")
+
+	v.list = widget.NewList(
+		func() int { return len(v.shown) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Wrapping = fyne.TextWrapOff
+			return l
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < len(v.shown) {
+				obj.(*widget.Label).SetText(v.shown[id].String())
+			}
+		},
+	)
+
+	v.level = widget.NewSelect([]string{
+		logLevelAll,
+		logs.LevelInfo.Label(),
+		logs.LevelWarn.Label(),
+		logs.LevelError.Label(),
+	}, func(string) { v.refresh() })
+	v.level.Selected = logLevelAll
+
+	v.search = widget.NewEntry()
+	v.search.SetPlaceHolder("搜索")
+	v.search.OnChanged = func(string) { v.refresh() }
+
+	v.follow = widget.NewCheck("跟随", func(on bool) {
+		if on {
+			v.refresh()
+		}
+	})
+	v.follow.Checked = true
+
+	v.count = widget.NewLabel("")
+
+	exportBtn := widget.NewButtonWithIcon("导出", theme.DownloadIcon(), v.export)
+	diagBtn := widget.NewButtonWithIcon("诊断包", theme.InfoIcon(), v.exportDiag)
+
+	toolbar := container.NewBorder(nil, nil,
+		container.NewHBox(v.level, v.follow),
+		container.NewHBox(v.count, exportBtn, diagBtn),
+		v.search,
+	)
+	v.win.SetContent(container.NewBorder(toolbar, nil, nil, nil, v.list))
+	v.win.Resize(fyne.NewSize(860, 520))
+	v.win.SetOnClosed(func() {
+		close(v.stop)
+		u.logViewer = nil
+	})
+
+	u.logViewer = v
+	v.refresh()
+	v.win.Show()
+	go v.loop()
+}
+
+func (v *logViewer) loop() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-v.stop:
+			return
+		case <-ticker.C:
+			fyne.Do(func() {
+				if v.follow.Checked {
+					v.refresh()
+				}
+			})
+		}
+	}
+}
+
+func (v *logViewer) refresh() {
+	min := logs.LevelDebug
+	if v.level.Selected != "" && v.level.Selected != logLevelAll {
+		min = logs.ParseLevel(v.level.Selected)
+	}
+	query := v.search.Text
+
+	all := v.u.mgr.Logs()
+	shown := make([]logs.Entry, 0, len(all))
+	for _, e := range all {
+		if e.Matches(min, query) {
+			shown = append(shown, e)
+		}
+	}
+	v.shown = shown
+	v.count.SetText(fmt.Sprintf("%d/%d", len(shown), len(all)))
+	v.list.Refresh()
+	if v.follow.Checked && len(shown) > 0 {
+		v.list.ScrollToBottom()
+	}
+}
+
+func (v *logViewer) export() {
+	v.saveZip("frpcx-logs-", "日志已导出到 ", v.u.mgr.ExportLogs)
+}
+
+func (v *logViewer) exportDiag() {
+	v.saveZip("frpcx-diag-", "诊断包已保存到 ", func(w io.Writer) error {
+		return diag.CreateBundle(w, v.u.cfg, v.u.mgr)
+	})
+}
+
+func (v *logViewer) saveZip(prefix, done string, write func(io.Writer) error) {
+	d := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, v.win)
+			return
+		}
+		if w == nil {
+			return
+		}
+		defer w.Close()
+		if err := write(w); err != nil {
+			log.Error("导出失败", "path", w.URI().Path(), "err", err)
+			dialog.ShowError(err, v.win)
+			return
+		}
+		dialog.ShowInformation("导出完成", done+w.URI().Path(), v.win)
+	}, v.win)
+	d.SetFileName(prefix + time.Now().Format("20060102-150405") + ".zip")
+	d.Show()
+}
@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/metrics"
+)
+
+type proxyRow struct {
+	label *widget.Label
+	line  *sparkline
+}
+
+type metricsPanel struct {
+	summary  *widget.Label
+	visitors *widget.Label
+	rows     map[string]*proxyRow
+	box      *fyne.Container
+	card     *widget.Card
+}
+
+func newMetricsPanel() *metricsPanel {
+	p := &metricsPanel{
+		summary:  widget.NewLabel(""),
+		visitors: widget.NewLabel(""),
+		rows:     map[string]*proxyRow{},
+		box:      container.NewVBox(),
+	}
+	p.card = widget.NewCard("流量", "", container.NewVBox(p.summary, p.box, p.visitors))
+	p.card.Hide()
+	return p
+}
+
+func (u *App) refreshMetrics() {
+	if u.metricsPanel == nil || u.collector == nil {
+		return
+	}
+	p := u.metricsPanel
+	snap := u.mgr.Status()
+
+	var summary []string
+	for _, s := range u.mgr.Stats() {
+		if s.Profile != snap.ProfileName {
+			continue
+		}
+		if s.Running {
+			summary = append(summary, "运行 "+formatDuration(time.Since(s.RunningSince)))
+		}
+		summary = append(summary, fmt.Sprintf("重启 %d", s.Restarts), fmt.Sprintf("重连 %d", s.Reconnects))
+	}
+	p.summary.SetText(strings.Join(summary, " · "))
+
+	visitors := make([]string, 0, len(snap.Visitors))
+	for _, v := range snap.Visitors {
+		visitors = append(visitors, visitorStatusText(v))
+	}
+	p.visitors.SetText(strings.Join(visitors, "\n"))
+	if len(visitors) == 0 {
+		p.visitors.Hide()
+	} else {
+		p.visitors.Show()
+	}
+
+	proxies := u.collector.Proxies()
+	seen := map[string]bool{}
+	for _, px := range proxies {
+		if px.Profile != snap.ProfileName {
+			continue
+		}
+		key := px.Profile + "/" + px.Name
+		seen[key] = true
+		row, ok := p.rows[key]
+		if !ok {
+			row = &proxyRow{label: widget.NewLabel(""), line: newSparkline(statusColor("running"))}
+			p.rows[key] = row
+			p.box.Add(container.NewBorder(nil, nil, nil, row.line, row.label))
+		}
+		row.label.SetText(proxyMetricsText(px))
+		row.line.SetValues(totalRates(px.History))
+	}
+	for key := range p.rows {
+		if !seen[key] {
+			delete(p.rows, key)
+			p.rebuild()
+			break
+		}
+	}
+
+	if snap.ProfileName == "" {
+		p.card.Hide()
+	} else {
+		p.card.Show()
+	}
+}
+
+func (p *metricsPanel) rebuild() {
+	p.box.RemoveAll()
+	for _, row := range p.rows {
+		p.box.Add(container.NewBorder(nil, nil, nil, row.line, row.label))
+	}
+}
+
+func proxyMetricsText(px metrics.ProxyMetrics) string {
+	var in, out float64
+	if n := len(px.History); n > 0 {
+		in = px.History[n-1].InRate
+		out = px.History[n-1].OutRate
+	}
+	text := fmt.Sprintf("%s  ↓%s/s ↑%s/s  今日 %s/%s  连接 %d",
+		px.Name, formatBytes(in), formatBytes(out),
+		formatBytes(float64(px.TrafficIn)), formatBytes(float64(px.TrafficOut)), px.Conns)
+	if px.Status != "" {
+		text += "  " + px.Status
+	}
+	return text
+}
+
+func totalRates(history []metrics.Sample) []float64 {
+	out := make([]float64, 0, len(history))
+	for _, s := range history {
+		out = append(out, s.InRate+s.OutRate)
+	}
+	return out
+}
+
+func formatBytes(v float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f%s", v, units[i])
+	}
+	return fmt.Sprintf("%.1f%s", v, units[i])
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm", h, m)
+	}
+	if m > 0 {
+		return fmt.Sprintf("%dm%02ds", m, s)
+	}
+	return fmt.Sprintf("%ds", s)
+}
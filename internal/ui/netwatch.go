@@ -0,0 +1,21 @@
+package ui
+
+import (
+	"time"
+
+	"frpcx/internal/netwatch"
+)
+
+func (u *App) applyNetWatch() {
+	if u.netwatch != nil {
+		u.netwatch.Stop()
+		u.netwatch = nil
+	}
+	if !u.cfg.NetWatch.Enabled {
+		return
+	}
+	poll := time.Duration(u.cfg.NetWatch.PollSec) * time.Second
+	u.netwatch = netwatch.Start(poll, func(e netwatch.Event) {
+		go u.mgr.Recheck(e.Detail)
+	})
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"frpcx/internal/config"
+	"frpcx/internal/frpc"
+)
+
+type notifier struct {
+	app fyne.App
+
+	mu         sync.Mutex
+	cfg        config.NotifyConfig
+	last       map[frpc.EventType]time.Time
+	suppressed map[frpc.EventType]int
+}
+
+func newNotifier(a fyne.App, cfg config.NotifyConfig) *notifier {
+	return &notifier{
+		app:        a,
+		cfg:        cfg,
+		last:       map[frpc.EventType]time.Time{},
+		suppressed: map[frpc.EventType]int{},
+	}
+}
+
+func (n *notifier) SetConfig(cfg config.NotifyConfig) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cfg = cfg
+}
+
+func (n *notifier) Handle(e frpc.Event) {
+	title, content, ok := n.describe(e)
+	if !ok {
+		return
+	}
+
+	n.mu.Lock()
+	interval := time.Duration(n.cfg.MinIntervalSec) * time.Second
+	if last, seen := n.last[e.Type]; seen && interval > 0 && e.Time.Sub(last) < interval {
+		n.suppressed[e.Type]++
+		n.mu.Unlock()
+		log.Debug("通知已限流", "type", string(e.Type), "profile", e.Profile)
+		return
+	}
+	if c := n.suppressed[e.Type]; c > 0 {
+		content += fmt.Sprintf("（期间另有 %d 条同类通知）", c)
+	}
+	n.last[e.Type] = e.Time
+	n.suppressed[e.Type] = 0
+	n.mu.Unlock()
+
+	n.app.SendNotification(fyne.NewNotification(title, content))
+}
+
+func (n *notifier) describe(e frpc.Event) (string, string, bool) {
+	n.mu.Lock()
+	cfg := n.cfg
+	n.mu.Unlock()
+
+	switch e.Type {
+	case frpc.EventReady:
+		return "穿透已启动", fmt.Sprintf("配置“%s”运行中", e.Profile), cfg.OnStart
+	case frpc.EventFailed:
+		return "穿透失败", e.Message, cfg.OnFailure
+	case frpc.EventFailover:
+		return "已切换配置", fmt.Sprintf("“%s”不可用，已切换到“%s”", e.From, e.Profile), cfg.OnFailover
+	case frpc.EventFailback:
+		return "已切回首选配置", fmt.Sprintf("“%s”已恢复稳定，已从“%s”切回", e.Profile, e.From), cfg.OnFailover
+	case frpc.EventDegraded:
+		return "穿透状态异常", fmt.Sprintf("配置“%s”状态检查失败: %s", e.Profile, e.Message), cfg.OnDegraded
+	case frpc.EventRecovered:
+		return "穿透状态恢复", fmt.Sprintf("配置“%s”状态检查恢复正常", e.Profile), cfg.OnDegraded
+	}
+	return "", "", false
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/frpc"
+)
+
+func (u *App) checkOrphans() bool {
+	orphans := frpc.FindOrphans()
+	if len(orphans) == 0 {
+		return false
+	}
+	log.Warn("发现残留的 frpc 进程", "count", len(orphans))
+	fyne.Do(func() {
+		u.showOrphans(orphans)
+	})
+	return true
+}
+
+func (u *App) showOrphans(orphans []frpc.RunRecord) {
+	var d dialog.Dialog
+	rows := container.NewVBox()
+	remaining := len(orphans)
+	done := func() {
+		remaining--
+		if remaining <= 0 && d != nil {
+			d.Hide()
+		}
+	}
+
+	for _, rec := range orphans {
+		rec := rec
+		label := widget.NewLabel(fmt.Sprintf("配置“%s” PID %d，启动于 %s", rec.Profile, rec.PID, rec.StartedAt.Format("2006-01-02 15:04:05")))
+		var adoptBtn, killBtn *widget.Button
+		adoptBtn = widget.NewButton("接管", func() {
+			adoptBtn.Disable()
+			killBtn.Disable()
+			go func() {
+				err := u.mgr.Adopt(rec)
+				fyne.Do(func() {
+					if err != nil {
+						u.errorLabel.SetText("接管失败: " + err.Error())
+						killBtn.Enable()
+						return
+					}
+					done()
+				})
+			}()
+		})
+		killBtn = widget.NewButton("终止", func() {
+			adoptBtn.Disable()
+			killBtn.Disable()
+			go func() {
+				err := frpc.TerminateOrphan(rec, 5*time.Second)
+				fyne.Do(func() {
+					if err != nil {
+						u.errorLabel.SetText(err.Error())
+						killBtn.Enable()
+						return
+					}
+					done()
+				})
+			}()
+		})
+		rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(adoptBtn, killBtn), label))
+	}
+
+	hint := widget.NewLabel("上次运行留下的 frpc 进程仍在运行，可接管继续监控，或终止后重新启动。")
+	hint.Wrapping = fyne.TextWrapWord
+	d = dialog.NewCustom("发现残留进程", "稍后处理", container.NewVBox(hint, rows), u.win)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/autostart"
+	"frpcx/internal/config"
+)
+
+func (u *App) showSettings() {
+	w := u.app.NewWindow("设置")
+
+	notify := u.cfg.Notify
+	onStart := widget.NewCheck("启动成功", nil)
+	onStart.SetChecked(notify.OnStart)
+	onFailure := widget.NewCheck("启动失败或进程退出", nil)
+	onFailure.SetChecked(notify.OnFailure)
+	onFailover := widget.NewCheck("切换到其他配置", nil)
+	onFailover.SetChecked(notify.OnFailover)
+	onDegraded := widget.NewCheck("状态检查异常/恢复", nil)
+	onDegraded.SetChecked(notify.OnDegraded)
+	interval := widget.NewEntry()
+	interval.SetText(strconv.Itoa(notify.MinIntervalSec))
+	interval.SetPlaceHolder("秒")
+
+	launchAtLogin := widget.NewCheck("登录系统后自动运行", nil)
+	launchAtLogin.SetChecked(autostart.Enabled())
+	if !autostart.Supported() {
+		launchAtLogin.Disable()
+	}
+	startTunnel := widget.NewCheck("启动后自动连接隧道", nil)
+	startTunnel.SetChecked(u.cfg.AutoStartTunnel)
+	netWatch := widget.NewCheck("网络切换或系统唤醒后立即检查隧道", nil)
+	netWatch.SetChecked(u.cfg.NetWatch.Enabled)
+
+	strategy := newStrategySelect(u.cfg.Strategy)
+	failback := widget.NewCheck("首选配置恢复稳定后自动切回", nil)
+	failback.SetChecked(u.cfg.FailBack.Enabled)
+	stableSec := u.cfg.FailBack.StableSec
+	if stableSec <= 0 {
+		stableSec = 120
+	}
+	stable := widget.NewEntry()
+	stable.SetText(strconv.Itoa(stableSec))
+	stable.SetPlaceHolder("秒")
+
+	errLabel := widget.NewLabel("")
+
+	selectCard := widget.NewCard("配置选择", "", container.NewVBox(
+		container.NewGridWithColumns(2, widget.NewLabel("启动/切换时的选择方式"), strategy),
+		failback,
+		container.NewGridWithColumns(2, widget.NewLabel("切回前需稳定（秒）"), stable),
+		u.newLatencyBox(),
+	))
+
+	launchCard := widget.NewCard("启动", "", container.NewVBox(launchAtLogin, startTunnel, netWatch))
+
+	notifyCard := widget.NewCard("桌面通知", "", container.NewVBox(
+		onStart, onFailure, onFailover, onDegraded,
+		container.NewGridWithColumns(2, widget.NewLabel("同类通知最小间隔（秒）"), interval),
+	))
+
+	saveBtn := widget.NewButton("保存", func() {
+		sec, err := strconv.Atoi(interval.Text)
+		if err != nil || sec < 0 {
+			errLabel.SetText("通知间隔无效")
+			return
+		}
+		stableSec, err := strconv.Atoi(stable.Text)
+		if err != nil || stableSec <= 0 {
+			errLabel.SetText("稳定时间无效")
+			return
+		}
+		if autostart.Supported() {
+			if err := autostart.Set(launchAtLogin.Checked); err != nil {
+				errLabel.SetText("设置开机自启失败: " + err.Error())
+				return
+			}
+		}
+		u.updateConfig(func(cfg *config.AppConfig) {
+			cfg.Notify = config.NotifyConfig{
+				OnStart:        onStart.Checked,
+				OnFailure:      onFailure.Checked,
+				OnFailover:     onFailover.Checked,
+				OnDegraded:     onDegraded.Checked,
+				MinIntervalSec: sec,
+			}
+			cfg.Autostart = launchAtLogin.Checked
+			cfg.AutoStartTunnel = startTunnel.Checked
+			cfg.Strategy = strategyValue(strategy.Selected)
+			cfg.FailBack.Enabled = failback.Checked
+			cfg.FailBack.StableSec = stableSec
+			cfg.NetWatch.Enabled = netWatch.Checked
+		})
+		u.applyNetWatch()
+		u.notifier.SetConfig(u.cfg.Notify)
+		w.Close()
+	})
+
+	w.SetContent(container.NewVBox(launchCard, selectCard, notifyCard, errLabel, saveBtn))
+	w.Resize(fyne.NewSize(420, 0))
+	w.Show()
+}
+
+func (u *App) updateConfig(fn func(cfg *config.AppConfig)) {
+	u.autoSaveMu.Lock()
+	defer u.autoSaveMu.Unlock()
+	fn(u.cfg)
+	if err := config.Save(u.cfg); err != nil {
+		log.Error("保存设置失败", "err", err)
+	}
+	u.mgr.SetConfig(u.cfg)
+}
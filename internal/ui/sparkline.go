@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+type sparkline struct {
+	widget.BaseWidget
+	values []float64
+	color  color.Color
+}
+
+func newSparkline(c color.Color) *sparkline {
+	s := &sparkline{color: c}
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+func (s *sparkline) SetValues(values []float64) {
+	s.values = append([]float64{}, values...)
+	s.Refresh()
+}
+
+func (s *sparkline) CreateRenderer() fyne.WidgetRenderer {
+	r := &sparklineRenderer{s: s}
+	r.rebuild()
+	return r
+}
+
+type sparklineRenderer struct {
+	s     *sparkline
+	lines []*canvas.Line
+	size  fyne.Size
+}
+
+func (r *sparklineRenderer) rebuild() {
+	n := len(r.s.values) - 1
+	if n < 0 {
+		n = 0
+	}
+	for len(r.lines) < n {
+		l := canvas.NewLine(r.s.color)
+		l.StrokeWidth = 1.5
+		r.lines = append(r.lines, l)
+	}
+	r.lines = r.lines[:n]
+	r.place()
+}
+
+func (r *sparklineRenderer) place() {
+	vals := r.s.values
+	if len(vals) < 2 || r.size.Width <= 0 || r.size.Height <= 0 {
+		return
+	}
+	max := 0.0
+	for _, v := range vals {
+		if v > max {
+			max = v
+		}
+	}
+	if max <= 0 {
+		max = 1
+	}
+	step := r.size.Width / float32(len(vals)-1)
+	y := func(v float64) float32 {
+		return r.size.Height - float32(v/max)*(r.size.Height-2) - 1
+	}
+	for i, l := range r.lines {
+		l.StrokeColor = r.s.color
+		l.Position1 = fyne.NewPos(float32(i)*step, y(vals[i]))
+		l.Position2 = fyne.NewPos(float32(i+1)*step, y(vals[i+1]))
+	}
+}
+
+func (r *sparklineRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.place()
+}
+
+func (r *sparklineRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(120, 24)
+}
+
+func (r *sparklineRenderer) Refresh() {
+	r.rebuild()
+	for _, l := range r.lines {
+		l.Refresh()
+	}
+}
+
+func (r *sparklineRenderer) Objects() []fyne.CanvasObject {
+	out := make([]fyne.CanvasObject, 0, len(r.lines))
+	for _, l := range r.lines {
+		out = append(out, l)
+	}
+	return out
+}
+
+func (r *sparklineRenderer) Destroy() {}
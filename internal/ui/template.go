@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/config"
+	"frpcx/internal/frpc"
+	"frpcx/internal/tmpl"
+)
+
+func (u *App) showTemplateEditor() {
+	w := u.app.NewWindow("从模板创建")
+
+	current := config.Profile{}
+	if p := u.currentProfile(); p != nil {
+		current = *p
+	}
+
+	pathEntry := widget.NewEntry()
+	pathEntry.SetPlaceHolder("模板 TOML 文件，变量写作 {{user}} 或 {{port:8000}}")
+	pathEntry.SetText(current.Template)
+	varsBox := container.NewVBox()
+	errLabel := widget.NewLabel("")
+	errLabel.Wrapping = fyne.TextWrapWord
+
+	var t *tmpl.Template
+	entries := map[string]*widget.Entry{}
+
+	load := func() {
+		varsBox.RemoveAll()
+		entries = map[string]*widget.Entry{}
+		t = nil
+		path := strings.TrimSpace(pathEntry.Text)
+		if path == "" {
+			errLabel.SetText("")
+			return
+		}
+		parsed, err := tmpl.ParseFile(path)
+		if err != nil {
+			errLabel.SetText(err.Error())
+			return
+		}
+		t = parsed
+		for _, v := range t.Vars {
+			e := widget.NewEntry()
+			if v.HasDefault {
+				e.SetPlaceHolder("默认 " + v.Default)
+			} else {
+				e.SetPlaceHolder("必填")
+			}
+			if val, ok := current.Vars[v.Name]; ok {
+				e.SetText(val)
+			}
+			label := v.Name
+			if v.Desc != "" {
+				label += "（" + v.Desc + "）"
+			}
+			entries[v.Name] = e
+			varsBox.Add(container.NewGridWithColumns(2, widget.NewLabel(label), e))
+		}
+		if len(t.Vars) == 0 {
+			varsBox.Add(widget.NewLabel("模板中没有变量"))
+		}
+		errLabel.SetText("")
+	}
+	load()
+
+	browseBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		dialog.ShowFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil || r == nil {
+				return
+			}
+			pathEntry.SetText(r.URI().Path())
+			_ = r.Close()
+			load()
+		}, w)
+	})
+	reloadBtn := widget.NewButtonWithIcon("读取", theme.ViewRefreshIcon(), load)
+
+	applyBtn := widget.NewButtonWithIcon("应用", theme.ConfirmIcon(), func() {
+		if t == nil {
+			errLabel.SetText("请先读取模板")
+			return
+		}
+		vars := map[string]string{}
+		for name, e := range entries {
+			if val := strings.TrimSpace(e.Text); val != "" {
+				vars[name] = val
+			}
+		}
+		if _, err := t.Resolve(vars); err != nil {
+			errLabel.SetText(err.Error())
+			return
+		}
+		if err := u.applyTemplate(strings.TrimSpace(pathEntry.Text), vars); err != nil {
+			errLabel.SetText(err.Error())
+			return
+		}
+		w.Close()
+	})
+
+	pathRow := container.NewBorder(nil, nil, widget.NewLabel("模板"), container.NewHBox(browseBtn, reloadBtn), pathEntry)
+	w.SetContent(container.NewVBox(
+		pathRow,
+		widget.NewSeparator(),
+		varsBox,
+		errLabel,
+		container.NewHBox(applyBtn),
+	))
+	w.Resize(fyne.NewSize(560, 0))
+	w.Show()
+}
+
+func (u *App) applyTemplate(path string, vars map[string]string) error {
+	if err := u.saveTemplateProfile(path, vars); err != nil {
+		return err
+	}
+	log.Info("已从模板创建配置", "template", path, "vars", len(vars))
+
+	u.suppressAutoSave = true
+	u.fillForm(u.loadFormFromCurrentProfile())
+	u.suppressAutoSave = false
+	u.setHint("已从模板生成配置，修改表单将脱离模板")
+	return nil
+}
+
+func (u *App) saveTemplateProfile(path string, vars map[string]string) error {
+	u.autoSaveMu.Lock()
+	defer u.autoSaveMu.Unlock()
+
+	profile := config.Profile{}
+	if p := u.currentProfile(); p != nil {
+		profile = *p
+	}
+	profile.Name = singleProfileName
+	profile.Enabled = true
+	profile.Template = path
+	profile.Vars = vars
+	cfgPath, err := frpc.RenderProfile(&profile)
+	if err != nil {
+		return err
+	}
+	profile.ConfigPath = cfgPath
+	if profile.StartTimeoutSec == 0 {
+		profile.StartTimeoutSec = 8
+	}
+	if profile.HealthTimeoutSec == 0 {
+		profile.HealthTimeoutSec = 3
+	}
+
+	u.cfg.ActiveProfile = singleProfileName
+	u.cfg.Profiles = []config.Profile{profile}
+	if err := config.Save(u.cfg); err != nil {
+		return err
+	}
+	u.mgr.SetConfig(u.cfg)
+	return nil
+}
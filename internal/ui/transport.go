@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/frpc"
+)
+
+func (u *App) showTransportEditor() {
+	w := u.app.NewWindow("传输设置")
+	t := u.transport
+
+	protocol := widget.NewSelect(frpc.TransportProtocols, nil)
+	tlsCheck := widget.NewCheck("启用 TLS", nil)
+	certFile := widget.NewEntry()
+	keyFile := widget.NewEntry()
+	caFile := widget.NewEntry()
+	serverName := widget.NewEntry()
+	tcpMux := widget.NewCheck("多路复用（tcpMux）", nil)
+	poolCount := widget.NewEntry()
+	hbInterval := widget.NewEntry()
+	hbTimeout := widget.NewEntry()
+	errLabel := widget.NewLabel("")
+
+	certFile.SetPlaceHolder("可选，客户端证书")
+	keyFile.SetPlaceHolder("可选，客户端私钥")
+	caFile.SetPlaceHolder("可选，用于校验服务器证书")
+	serverName.SetPlaceHolder("可选，默认为服务器地址")
+	poolCount.SetPlaceHolder("0 表示不预建连接")
+	hbInterval.SetPlaceHolder("秒，留空使用默认，-1 关闭")
+	hbTimeout.SetPlaceHolder("秒，留空使用默认，-1 关闭")
+
+	fill := func(t frpc.TransportOptions) {
+		protocol.SetSelected(t.Protocol)
+		tlsCheck.SetChecked(t.TLSEnable)
+		certFile.SetText(t.CertFile)
+		keyFile.SetText(t.KeyFile)
+		caFile.SetText(t.TrustedCaFile)
+		serverName.SetText(t.ServerName)
+		tcpMux.SetChecked(t.TCPMux)
+		poolCount.SetText(optionalInt(t.PoolCount))
+		hbInterval.SetText(optionalInt(t.HeartbeatInterval))
+		hbTimeout.SetText(optionalInt(t.HeartbeatTimeout))
+	}
+	fill(t)
+
+	row := func(label string, obj fyne.CanvasObject) fyne.CanvasObject {
+		return container.NewGridWithColumns(2, widget.NewLabel(label), obj)
+	}
+	tlsBox := container.NewVBox(
+		row("证书文件", fileEntry(w, certFile)),
+		row("私钥文件", fileEntry(w, keyFile)),
+		row("CA 证书", fileEntry(w, caFile)),
+		row("服务器名称", serverName),
+	)
+	tlsCheck.OnChanged = func(on bool) {
+		if on {
+			tlsBox.Show()
+		} else {
+			tlsBox.Hide()
+		}
+	}
+	tlsCheck.OnChanged(tlsCheck.Checked)
+
+	readInt := func(e *widget.Entry, what string) (int, bool) {
+		s := strings.TrimSpace(e.Text)
+		if s == "" {
+			return 0, true
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			errLabel.SetText(what + "无效: " + s)
+			return 0, false
+		}
+		return n, true
+	}
+
+	resetBtn := widget.NewButton("恢复默认", func() {
+		fill(frpc.DefaultTransport())
+	})
+	saveBtn := widget.NewButtonWithIcon("保存", theme.DocumentSaveIcon(), func() {
+		next := frpc.TransportOptions{
+			Protocol:      protocol.Selected,
+			TLSEnable:     tlsCheck.Checked,
+			CertFile:      strings.TrimSpace(certFile.Text),
+			KeyFile:       strings.TrimSpace(keyFile.Text),
+			TrustedCaFile: strings.TrimSpace(caFile.Text),
+			ServerName:    strings.TrimSpace(serverName.Text),
+			TCPMux:        tcpMux.Checked,
+		}
+		var ok bool
+		if next.PoolCount, ok = readInt(poolCount, "连接池大小"); !ok {
+			return
+		}
+		if next.HeartbeatInterval, ok = readInt(hbInterval, "心跳间隔"); !ok {
+			return
+		}
+		if next.HeartbeatTimeout, ok = readInt(hbTimeout, "心跳超时"); !ok {
+			return
+		}
+		if err := next.Validate(); err != nil {
+			errLabel.SetText(err.Error())
+			return
+		}
+		prev := u.transport
+		u.transport = next
+		if err := u.saveFormToGeneratedToml(u.readForm()); err != nil {
+			u.transport = prev
+			errLabel.SetText(err.Error())
+			return
+		}
+		log.Info("传输设置已保存", "protocol", next.Protocol, "tls", next.TLSEnable, "tcpMux", next.TCPMux)
+		w.Close()
+	})
+
+	w.SetContent(container.NewVBox(
+		row("协议", protocol),
+		tlsCheck,
+		tlsBox,
+		tcpMux,
+		row("连接池大小", poolCount),
+		row("心跳间隔（秒）", hbInterval),
+		row("心跳超时（秒）", hbTimeout),
+		errLabel,
+		container.NewHBox(resetBtn, saveBtn),
+	))
+	w.Resize(fyne.NewSize(520, 0))
+	w.Show()
+}
+
+func fileEntry(w fyne.Window, e *widget.Entry) fyne.CanvasObject {
+	btn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		dialog.ShowFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil || r == nil {
+				return
+			}
+			e.SetText(r.URI().Path())
+			_ = r.Close()
+		}, w)
+	})
+	return container.NewBorder(nil, nil, nil, btn, e)
+}
+
+func optionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func writeTransportToml(b *strings.Builder, t frpc.TransportOptions) {
+	str := func(key, val string) {
+		if val != "" {
+			b.WriteString(key + " = \"" + escapeTomlString(val) + "\"\n")
+		}
+	}
+	num := func(key string, val int) {
+		if val != 0 {
+			b.WriteString(key + " = " + strconv.Itoa(val) + "\n")
+		}
+	}
+	d := frpc.DefaultTransport()
+	if t.Protocol != d.Protocol {
+		str("transport.protocol", t.Protocol)
+	}
+	if !t.TLSEnable {
+		b.WriteString("transport.tls.enable = false\n")
+	}
+	str("transport.tls.certFile", t.CertFile)
+	str("transport.tls.keyFile", t.KeyFile)
+	str("transport.tls.trustedCaFile", t.TrustedCaFile)
+	str("transport.tls.serverName", t.ServerName)
+	if !t.TCPMux {
+		b.WriteString("transport.tcpMux = false\n")
+	}
+	num("transport.poolCount", t.PoolCount)
+	num("transport.heartbeatInterval", t.HeartbeatInterval)
+	num("transport.heartbeatTimeout", t.HeartbeatTimeout)
+}
@@ -0,0 +1,313 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+
+	"frpcx/internal/config"
+	"frpcx/internal/frpc"
+)
+
+type trayMenu struct {
+	desk   desktop.App
+	key    string
+	status string
+	icons  map[string]fyne.Resource
+}
+
+type publicURL struct {
+	Proxy string
+	URL   string
+}
+
+func (u *App) setupTray() {
+	desk, ok := u.app.(desktop.App)
+	if !ok {
+		return
+	}
+	u.tray = &trayMenu{desk: desk, icons: map[string]fyne.Resource{}}
+	u.refreshTray()
+}
+
+func (u *App) refreshTray() {
+	t := u.tray
+	if t == nil {
+		return
+	}
+
+	snap := u.mgr.Status()
+	if snap.Status != t.status {
+		t.status = snap.Status
+		t.desk.SetSystemTrayIcon(t.icon(snap.Status))
+	}
+
+	line := trayStatusLine(snap, u.uptime(snap.ProfileName))
+	cfgPath, mod := u.trayConfigFile()
+	key := strings.Join([]string{line, u.cfg.ActiveProfile, profileNames(u.cfg.Profiles), latencyKey(u.mgr.Latencies()), circuitKey(snap.Circuits), cfgPath, mod}, "|")
+	if key == t.key {
+		return
+	}
+	t.key = key
+
+	statusItem := fyne.NewMenuItem(line, nil)
+	statusItem.Disabled = true
+
+	showItem := fyne.NewMenuItem("显示", func() { u.win.Show() })
+	hideItem := fyne.NewMenuItem("隐藏", func() { u.win.Hide() })
+	startItem := fyne.NewMenuItem("启动", func() { go u.startChecked() })
+	stopItem := fyne.NewMenuItem("停止", func() { go u.mgr.Stop() })
+	checkItem := fyne.NewMenuItem("立即检查", u.checkNow)
+	checkItem.Disabled = snap.Status != "running"
+	quitItem := fyne.NewMenuItem("退出", func() { u.app.Quit() })
+
+	items := []*fyne.MenuItem{statusItem, fyne.NewMenuItemSeparator(), showItem, hideItem, startItem, stopItem, checkItem}
+	if switchItem := u.trayProfilesItem(snap); switchItem != nil {
+		items = append(items, switchItem)
+	}
+	if copyItem := u.trayURLsItem(cfgPath); copyItem != nil {
+		items = append(items, copyItem)
+	}
+	items = append(items, fyne.NewMenuItemSeparator(), quitItem)
+
+	t.desk.SetSystemTrayMenu(fyne.NewMenu("穿透助手", items...))
+}
+
+func (u *App) trayProfilesItem(snap frpc.StatusSnapshot) *fyne.MenuItem {
+	latencies := map[string]frpc.Latency{}
+	for _, l := range u.mgr.Latencies() {
+		latencies[l.Profile] = l
+	}
+	open := map[string]bool{}
+	for _, c := range snap.Circuits {
+		open[c.Profile] = c.State == frpc.CircuitOpen
+	}
+	var children []*fyne.MenuItem
+	for _, p := range u.cfg.Profiles {
+		if !p.Enabled {
+			continue
+		}
+		name := p.Name
+		label := name
+		if l, ok := latencies[name]; ok && l.OK() {
+			label += "  " + formatRTT(l.RTT)
+		}
+		if open[name] {
+			label += "  （熔断中）"
+		}
+		item := fyne.NewMenuItem(label, func() { u.switchProfile(name) })
+		if snap.ProfileName != "" {
+			item.Checked = name == snap.ProfileName
+		} else {
+			item.Checked = name == u.cfg.ActiveProfile
+		}
+		children = append(children, item)
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	item := fyne.NewMenuItem("切换配置", nil)
+	item.ChildMenu = fyne.NewMenu("", children...)
+	return item
+}
+
+func (u *App) trayURLsItem(cfgPath string) *fyne.MenuItem {
+	if cfgPath == "" {
+		return nil
+	}
+	info, err := frpc.LoadClientInfo(cfgPath)
+	if err != nil {
+		log.Debug("读取代理信息失败", "path", cfgPath, "err", err)
+		return nil
+	}
+	urls := publicURLs(info)
+	if len(urls) == 0 {
+		return nil
+	}
+	children := make([]*fyne.MenuItem, 0, len(urls))
+	for _, pu := range urls {
+		value := pu.URL
+		children = append(children, fyne.NewMenuItem(pu.Proxy+"  "+value, func() {
+			u.app.Clipboard().SetContent(value)
+			log.Info("已复制公网地址", "url", value)
+		}))
+	}
+	item := fyne.NewMenuItem("复制公网地址", nil)
+	item.ChildMenu = fyne.NewMenu("", children...)
+	return item
+}
+
+func (u *App) switchProfile(name string) {
+	go func() {
+		u.updateConfig(func(cfg *config.AppConfig) {
+			cfg.ActiveProfile = name
+		})
+		if err := u.mgr.Switch(name); err != nil {
+			log.Error("切换配置失败", "profile", name, "err", err)
+			u.app.SendNotification(fyne.NewNotification("切换配置失败", err.Error()))
+		}
+	}()
+}
+
+func (u *App) checkNow() {
+	go func() {
+		err := u.mgr.CheckStatusNow()
+		msg := "状态检查正常"
+		if err != nil {
+			msg = "状态检查失败: " + err.Error()
+		}
+		u.setHint(msg)
+		u.app.SendNotification(fyne.NewNotification("穿透助手", msg))
+	}()
+}
+
+func (u *App) uptime(profile string) time.Duration {
+	if profile == "" {
+		return 0
+	}
+	for _, s := range u.mgr.Stats() {
+		if s.Profile == profile && s.Running && !s.RunningSince.IsZero() {
+			return time.Since(s.RunningSince)
+		}
+	}
+	return 0
+}
+
+func (u *App) trayConfigFile() (string, string) {
+	path := ""
+	if _, active, ok := u.mgr.Active(); ok {
+		path = active
+	} else if p := u.currentProfile(); p != nil {
+		path = p.ConfigPath
+	}
+	if path == "" {
+		return "", ""
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return "", ""
+	}
+	return path, strconv.FormatInt(st.ModTime().UnixNano(), 10)
+}
+
+func trayStatusLine(snap frpc.StatusSnapshot, uptime time.Duration) string {
+	line := "● " + statusName(snap.Status)
+	if snap.ProfileName != "" {
+		line += " · " + snap.ProfileName
+	}
+	if snap.Status == "running" && uptime > 0 {
+		line += " · " + formatUptime(uptime)
+	}
+	return line
+}
+
+func statusName(status string) string {
+	switch status {
+	case "starting":
+		return "启动中"
+	case "running":
+		return "运行中"
+	case "error":
+		return "错误"
+	default:
+		return "已停止"
+	}
+}
+
+func formatUptime(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	if d < time.Minute {
+		return "不到 1 分钟"
+	}
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	if h >= 24 {
+		return fmt.Sprintf("%d 天 %d 小时", h/24, h%24)
+	}
+	if h > 0 {
+		return fmt.Sprintf("%d 小时 %d 分钟", h, m)
+	}
+	return fmt.Sprintf("%d 分钟", m)
+}
+
+func profileNames(profiles []config.Profile) string {
+	names := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		if p.Enabled {
+			names = append(names, p.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func publicURLs(info *frpc.ClientInfo) []publicURL {
+	var out []publicURL
+	for _, p := range info.Proxies {
+		switch p.Type {
+		case "http", "https":
+			for _, d := range p.CustomDomains {
+				if d = strings.TrimSpace(d); d != "" {
+					out = append(out, publicURL{Proxy: p.Name, URL: p.Type + "://" + d})
+				}
+			}
+		case "tcp", "udp":
+			if p.RemotePort > 0 && info.ServerAddr != "" {
+				out = append(out, publicURL{Proxy: p.Name, URL: net.JoinHostPort(info.ServerAddr, strconv.Itoa(p.RemotePort))})
+			}
+		}
+	}
+	return out
+}
+
+func (t *trayMenu) icon(status string) fyne.Resource {
+	if res, ok := t.icons[status]; ok {
+		return res
+	}
+	res := fyne.NewStaticResource("tray-"+status+".png", trayIconPNG(status))
+	t.icons[status] = res
+	return res
+}
+
+func trayIconPNG(status string) []byte {
+	const size = 64
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	fill := statusColor(status)
+	center := float64(size-1) / 2
+	radius := float64(size)/2 - 4
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)-center, float64(y)-center
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(x, y, fill)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func circuitKey(circuits []frpc.Circuit) string {
+	parts := make([]string, 0, len(circuits))
+	for _, c := range circuits {
+		parts = append(parts, c.Profile+"="+c.State)
+	}
+	return strings.Join(parts, ",")
+}
+
+func latencyKey(results []frpc.Latency) string {
+	parts := make([]string, 0, len(results))
+	for _, l := range results {
+		parts = append(parts, l.Profile+"="+formatRTT(l.RTT))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/config"
+	"frpcx/internal/frpc"
+)
+
+const builtinVersion = "内置"
+
+var plainVersionRe = regexp.MustCompile(`^v?\d+\.\d+\.\d+$`)
+
+type versionsWindow struct {
+	u        *App
+	win      fyne.Window
+	profile  string
+	selected *widget.Select
+	list     *fyne.Container
+	source   *widget.Entry
+	status   *widget.Label
+	busy     bool
+}
+
+func (u *App) showVersions() {
+	p := u.currentProfile()
+	if p == nil {
+		u.errorLabel.SetText("请先填写并保存配置")
+		return
+	}
+
+	v := &versionsWindow{u: u, profile: p.Name}
+	v.win = u.app.NewWindow("frpc 版本")
+	v.selected = widget.NewSelect(nil, v.choose)
+	v.list = container.NewVBox()
+	v.status = widget.NewLabel("")
+	v.status.Wrapping = fyne.TextWrapWord
+
+	v.source = widget.NewEntry()
+	v.source.SetPlaceHolder("版本号（如 0.61.1）或安装包下载地址")
+	downloadBtn := widget.NewButtonWithIcon("下载", theme.DownloadIcon(), v.download)
+	importBtn := widget.NewButtonWithIcon("从文件导入", theme.FolderOpenIcon(), v.importFile)
+
+	help := widget.NewLabel("安装包需与 " + frpc.ChecksumsFile + " 一起提供，导入前会校验 SHA-256。")
+	help.Wrapping = fyne.TextWrapWord
+
+	top := container.NewVBox(
+		container.NewGridWithColumns(2, widget.NewLabel("当前配置使用"), v.selected),
+		widget.NewSeparator(),
+	)
+	bottom := container.NewVBox(
+		widget.NewSeparator(),
+		container.NewBorder(nil, nil, nil, container.NewHBox(downloadBtn, importBtn), v.source),
+		help,
+		v.status,
+	)
+	v.win.SetContent(container.NewBorder(top, bottom, nil, nil, container.NewVScroll(v.list)))
+	v.win.Resize(fyne.NewSize(560, 380))
+	v.reload()
+	v.win.Show()
+}
+
+func (v *versionsWindow) reload() {
+	versions, err := frpc.ListVersions()
+	if err != nil {
+		v.status.SetText("读取已安装版本失败: " + err.Error())
+	}
+
+	current := ""
+	if p, ok := v.u.profileByName(v.profile); ok {
+		current = p.FrpcVersion
+	}
+
+	options := []string{builtinVersion}
+	v.list.Objects = nil
+	for _, iv := range versions {
+		iv := iv
+		options = append(options, iv.Version)
+		label := widget.NewLabel(iv.Version + "  安装于 " + iv.InstalledAt.Format("2006-01-02 15:04"))
+		removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() { v.remove(iv.Version) })
+		if iv.Version == current {
+			removeBtn.Disable()
+		}
+		v.list.Add(container.NewBorder(nil, nil, nil, removeBtn, label))
+	}
+	if len(versions) == 0 {
+		v.list.Add(widget.NewLabel("尚未安装其他版本"))
+	}
+	v.list.Refresh()
+
+	v.selected.Options = options
+	if current == "" {
+		current = builtinVersion
+	}
+	v.selected.Selected = current
+	v.selected.Refresh()
+}
+
+func (v *versionsWindow) choose(version string) {
+	if version == builtinVersion {
+		version = ""
+	}
+	p, ok := v.u.profileByName(v.profile)
+	if !ok || p.FrpcVersion == version {
+		return
+	}
+	v.u.updateConfig(func(cfg *config.AppConfig) {
+		for i := range cfg.Profiles {
+			if cfg.Profiles[i].Name == v.profile {
+				cfg.Profiles[i].FrpcVersion = version
+			}
+		}
+	})
+	log.Info("已选择 frpc 版本", "profile", v.profile, "version", version)
+	v.status.SetText("已保存，重新启动后生效")
+	v.reload()
+	go v.u.refreshFrpcVersion()
+}
+
+func (v *versionsWindow) remove(version string) {
+	dialog.ShowConfirm("删除版本", "确定删除 frpc "+version+"？", func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := frpc.RemoveVersion(version); err != nil {
+			v.status.SetText(err.Error())
+		}
+		v.reload()
+	}, v.win)
+}
+
+func (v *versionsWindow) download() {
+	src := strings.TrimSpace(v.source.Text)
+	if src == "" {
+		v.status.SetText("请输入版本号或下载地址")
+		return
+	}
+	if plainVersionRe.MatchString(src) {
+		src = frpc.ReleaseURL(src)
+	}
+	v.run("正在下载 "+src, func() (frpc.InstalledVersion, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		return frpc.InstallFromURL(ctx, src, "")
+	})
+}
+
+func (v *versionsWindow) importFile() {
+	dialog.ShowFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil {
+			v.status.SetText(err.Error())
+			return
+		}
+		if r == nil {
+			return
+		}
+		archive := r.URI().Path()
+		r.Close()
+
+		sums := filepath.Join(filepath.Dir(archive), frpc.ChecksumsFile)
+		if _, err := os.Stat(sums); err == nil {
+			v.importArchive(archive, sums)
+			return
+		}
+		d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil {
+				v.status.SetText(err.Error())
+				return
+			}
+			if r == nil {
+				return
+			}
+			sums := r.URI().Path()
+			r.Close()
+			v.importArchive(archive, sums)
+		}, v.win)
+		d.SetFilter(storage.NewExtensionFileFilter([]string{".txt"}))
+		d.SetConfirmText("选择校验文件")
+		d.Show()
+	}, v.win)
+}
+
+func (v *versionsWindow) importArchive(archive, sums string) {
+	v.run("正在导入 "+filepath.Base(archive), func() (frpc.InstalledVersion, error) {
+		return frpc.InstallFromFile(archive, sums)
+	})
+}
+
+func (v *versionsWindow) run(msg string, fn func() (frpc.InstalledVersion, error)) {
+	if v.busy {
+		return
+	}
+	v.busy = true
+	v.status.SetText(msg)
+	go func() {
+		iv, err := fn()
+		fyne.Do(func() {
+			v.busy = false
+			if err != nil {
+				log.Error("安装 frpc 失败", "err", err)
+				v.status.SetText("安装失败: " + err.Error())
+				return
+			}
+			v.status.SetText("已安装 frpc " + iv.Version)
+			v.source.SetText("")
+			v.reload()
+		})
+	}()
+}
+
+func (u *App) profileByName(name string) (config.Profile, bool) {
+	for _, p := range u.cfg.Profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return config.Profile{}, false
+}
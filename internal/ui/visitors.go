@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"frpcx/internal/frpc"
+)
+
+type visitorRow struct {
+	name            *widget.Entry
+	kind            *widget.Select
+	serverUser      *widget.Entry
+	serverName      *widget.Entry
+	secretKey       *widget.Entry
+	bindAddr        *widget.Entry
+	bindPort        *widget.Entry
+	protocol        *widget.Select
+	keepTunnelOpen  *widget.Check
+	fallbackTo      *widget.Entry
+	fallbackTimeout *widget.Entry
+}
+
+func (r *visitorRow) read() (frpc.VisitorInfo, error) {
+	v := frpc.VisitorInfo{
+		Name:           strings.TrimSpace(r.name.Text),
+		Type:           r.kind.Selected,
+		ServerUser:     strings.TrimSpace(r.serverUser.Text),
+		ServerName:     strings.TrimSpace(r.serverName.Text),
+		SecretKey:      r.secretKey.Text,
+		BindAddr:       strings.TrimSpace(r.bindAddr.Text),
+		KeepTunnelOpen: r.keepTunnelOpen.Checked,
+		FallbackTo:     strings.TrimSpace(r.fallbackTo.Text),
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(r.bindPort.Text))
+	if err != nil {
+		return v, fmt.Errorf("访问者 %s 的本地端口无效: %s", v.Name, r.bindPort.Text)
+	}
+	v.BindPort = port
+	if v.Type == "xtcp" {
+		v.Protocol = r.protocol.Selected
+		if t := strings.TrimSpace(r.fallbackTimeout.Text); t != "" {
+			ms, err := strconv.Atoi(t)
+			if err != nil {
+				return v, fmt.Errorf("访问者 %s 的回退超时无效: %s", v.Name, t)
+			}
+			v.FallbackTimeoutMs = ms
+		}
+	} else {
+		v.KeepTunnelOpen = false
+		v.FallbackTo = ""
+	}
+	return v, nil
+}
+
+func (u *App) showVisitorEditor() {
+	w := u.app.NewWindow("访问者")
+
+	var rows []*visitorRow
+	list := container.NewVBox()
+	errLabel := widget.NewLabel("")
+	errLabel.Wrapping = fyne.TextWrapWord
+
+	addRow := func(v frpc.VisitorInfo) {
+		r := &visitorRow{
+			name:            widget.NewEntry(),
+			kind:            widget.NewSelect(frpc.VisitorTypes, nil),
+			serverUser:      widget.NewEntry(),
+			serverName:      widget.NewEntry(),
+			secretKey:       widget.NewPasswordEntry(),
+			bindAddr:        widget.NewEntry(),
+			bindPort:        widget.NewEntry(),
+			protocol:        widget.NewSelect([]string{"quic", "kcp"}, nil),
+			keepTunnelOpen:  widget.NewCheck("保持隧道", nil),
+			fallbackTo:      widget.NewEntry(),
+			fallbackTimeout: widget.NewEntry(),
+		}
+		r.name.SetPlaceHolder("名称")
+		r.serverUser.SetPlaceHolder("对方用户（可选）")
+		r.serverName.SetPlaceHolder("对方代理名称")
+		r.secretKey.SetPlaceHolder("secretKey")
+		r.bindAddr.SetPlaceHolder("127.0.0.1")
+		r.bindPort.SetPlaceHolder("本地端口")
+		r.protocol.PlaceHolder = "协议（默认 quic）"
+		r.fallbackTo.SetPlaceHolder("回退到 stcp 访问者（可选）")
+		r.fallbackTimeout.SetPlaceHolder("回退超时毫秒")
+
+		r.name.SetText(v.Name)
+		r.serverUser.SetText(v.ServerUser)
+		r.serverName.SetText(v.ServerName)
+		r.secretKey.SetText(v.SecretKey)
+		r.bindAddr.SetText(v.BindAddr)
+		if v.BindPort != 0 {
+			r.bindPort.SetText(strconv.Itoa(v.BindPort))
+		}
+		if v.Protocol != "" {
+			r.protocol.SetSelected(v.Protocol)
+		}
+		r.keepTunnelOpen.SetChecked(v.KeepTunnelOpen)
+		r.fallbackTo.SetText(v.FallbackTo)
+		r.fallbackTimeout.SetText(optionalInt(v.FallbackTimeoutMs))
+
+		xtcpRow := container.NewGridWithColumns(4, r.protocol, r.keepTunnelOpen, r.fallbackTo, r.fallbackTimeout)
+		r.kind.OnChanged = func(t string) {
+			if t == "xtcp" {
+				xtcpRow.Show()
+			} else {
+				xtcpRow.Hide()
+			}
+		}
+		if v.Type == "" {
+			v.Type = "stcp"
+		}
+		r.kind.SetSelected(v.Type)
+
+		var card *fyne.Container
+		removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			for i := range rows {
+				if rows[i] == r {
+					rows = append(rows[:i], rows[i+1:]...)
+					break
+				}
+			}
+			list.Remove(card)
+		})
+		card = container.NewVBox(
+			container.NewBorder(nil, nil, nil, removeBtn, container.NewGridWithColumns(2, r.name, r.kind)),
+			container.NewGridWithColumns(2, r.serverUser, r.serverName),
+			container.NewGridWithColumns(3, r.secretKey, r.bindAddr, r.bindPort),
+			xtcpRow,
+			widget.NewSeparator(),
+		)
+		rows = append(rows, r)
+		list.Add(card)
+	}
+	for _, v := range u.visitors {
+		addRow(v)
+	}
+
+	addBtn := widget.NewButtonWithIcon("添加", theme.ContentAddIcon(), func() {
+		addRow(frpc.VisitorInfo{Type: "stcp"})
+	})
+	saveBtn := widget.NewButtonWithIcon("保存", theme.DocumentSaveIcon(), func() {
+		out := make([]frpc.VisitorInfo, 0, len(rows))
+		for _, r := range rows {
+			v, err := r.read()
+			if err != nil {
+				errLabel.SetText(err.Error())
+				return
+			}
+			out = append(out, v)
+		}
+		if err := frpc.ValidateVisitors(out); err != nil {
+			errLabel.SetText(err.Error())
+			return
+		}
+		prev := u.visitors
+		u.visitors = out
+		if err := u.saveFormToGeneratedToml(u.readForm()); err != nil {
+			u.visitors = prev
+			errLabel.SetText(err.Error())
+			return
+		}
+		log.Info("访问者已保存", "count", len(out))
+		w.Close()
+	})
+
+	help := widget.NewLabel("访问者用于连接他人的 stcp / xtcp / sudp 代理，在本机监听端口后转发；对方代理需使用相同的 secretKey。")
+	help.Wrapping = fyne.TextWrapWord
+
+	w.SetContent(container.NewBorder(help, container.NewVBox(errLabel, container.NewHBox(addBtn, saveBtn)), nil, nil, container.NewVScroll(list)))
+	w.Resize(fyne.NewSize(680, 420))
+	w.Show()
+}
+
+func writeVisitorsToml(b *strings.Builder, visitors []frpc.VisitorInfo) {
+	str := func(key, val string) {
+		if val != "" {
+			b.WriteString(key + " = \"" + escapeTomlString(val) + "\"\n")
+		}
+	}
+	for _, v := range visitors {
+		b.WriteString("\n[[visitors]]\n")
+		str("name", v.Name)
+		str("type", v.Type)
+		str("serverUser", v.ServerUser)
+		str("serverName", v.ServerName)
+		str("secretKey", v.SecretKey)
+		str("bindAddr", v.BindAddr)
+		b.WriteString("bindPort = " + strconv.Itoa(v.BindPort) + "\n")
+		str("protocol", v.Protocol)
+		if v.KeepTunnelOpen {
+			b.WriteString("keepTunnelOpen = true\n")
+		}
+		str("fallbackTo", v.FallbackTo)
+		if v.FallbackTimeoutMs > 0 {
+			b.WriteString("fallbackTimeoutMs = " + strconv.Itoa(v.FallbackTimeoutMs) + "\n")
+		}
+	}
+}
+
+func visitorStatusText(v frpc.VisitorStatus) string {
+	text := "访问者 " + v.Name + " → " + v.ServerName + "（" + v.Type + "）"
+	switch {
+	case v.Bind == "":
+		text += "  不监听本地端口"
+	case v.Listening:
+		text += "  " + v.Bind + "  监听中"
+	default:
+		text += "  " + v.Bind + "  未监听"
+	}
+	return text
+}
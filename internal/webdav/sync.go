@@ -8,8 +8,11 @@ import (
     "github.com/studio-b12/gowebdav"
 
     "frpcx/internal/config"
+    "frpcx/internal/logs"
 )
 
+var log = logs.For("webdav")
+
 func SyncProfiles(cfg *config.AppConfig) (map[string]string, error) {
     if cfg.WebDAV.URL == "" || cfg.WebDAV.Username == "" || cfg.WebDAV.Password == "" {
         return nil, errors.New("WebDAV 配置不完整")
@@ -40,6 +43,7 @@ func SyncProfiles(cfg *config.AppConfig) (map[string]string, error) {
         }
         data, err := client.Read(remote)
         if err != nil {
+            log.Error("下载远程配置失败", "profile", p.Name, "remote", remote, "err", err)
             return updated, err
         }
         localPath, err := cachedPathForProfile(p.Name)
@@ -50,6 +54,7 @@ func SyncProfiles(cfg *config.AppConfig) (map[string]string, error) {
             return updated, err
         }
         updated[p.Name] = localPath
+        log.Info("远程配置已同步", "profile", p.Name, "remote", remote, "bytes", len(data))
     }
     return updated, nil
 }
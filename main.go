@@ -1,16 +1,40 @@
 package main
 
 import (
-    "log"
+    "os"
 
     "frpcx/internal/config"
+    "frpcx/internal/logs"
     "frpcx/internal/ui"
 )
 
+var log = logs.For("main")
+
 func main() {
     cfg, err := config.Load()
     if err != nil {
-        log.Fatalf("加载配置失败: %v", err)
+        log.Error("加载配置失败", "err", err)
+        os.Exit(1)
+    }
+
+    logDir, err := config.LogDir()
+    if err != nil {
+        log.Warn("无法确定日志目录，日志仅保存在内存中", "err", err)
     }
+    logs.SetLevel(cfg.Logs.Level)
+    store := logs.Setup(logDir, logs.Options{
+        MaxSizeMB:  cfg.Logs.MaxSizeMB,
+        MaxAgeDays: cfg.Logs.MaxAgeDays,
+        MaxBackups: cfg.Logs.MaxBackups,
+    })
+    defer store.Close()
+
+    if len(os.Args) > 1 {
+        store.SetConsole(os.Stderr)
+        code := runCLI(cfg, os.Args[1:])
+        store.Close()
+        os.Exit(code)
+    }
+
     ui.Run(cfg)
 }